@@ -7,13 +7,26 @@ import (
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"gorm.io/gorm"
 )
 
 // please add new model in next index for consistency migrate and rollback
 var tables = []interface{}{
+	&model.Permission{},
 	&model.Role{},
+	&model.RolePermission{},
 	&model.Division{},
 	&model.Employee{},
+	&model.RefreshToken{},
+	&model.PasswordResetToken{},
+	&model.EmployeeMFA{},
+	&model.MFARecoveryCode{},
+	&model.APIKey{},
+	&model.ImpersonationSession{},
+	&model.EmployeeInvitation{},
+	&model.EmployeeStatusChange{},
+	&model.EmployeePendingChange{},
 }
 
 func Migrate() {
@@ -21,6 +34,9 @@ func Migrate() {
 	conn := database.GetConnection()
 
 	conn.AutoMigrate(tables...)
+
+	syncPermissions(conn)
+	backfillEmployeeProfiles(conn)
 }
 
 func Rollback() {
@@ -57,4 +73,47 @@ func Status() {
 			fmt.Println("\t", name, "===>", string(colorYellow), "not migrated", string(colorReset))
 		}
 	}
-}
\ No newline at end of file
+}
+
+// syncPermissions creates the permissions known by the code. Permissions that
+// did not exist yet are granted to the Admin role, so admins keep full access
+// as new permissions are introduced.
+func syncPermissions(conn *gorm.DB) {
+	var created []model.Permission
+	for _, p := range enum.Permissions {
+		permission := model.Permission{Name: string(p)}
+		result := conn.Where(permission).Attrs(model.Permission{Description: p.Description()}).FirstOrCreate(&permission)
+		if result.Error != nil {
+			fmt.Printf("cannot sync permission %s: %v\n", p, result.Error)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			created = append(created, permission)
+		}
+	}
+
+	if len(created) == 0 {
+		return
+	}
+	var admin model.Role
+	if err := conn.Where("id = ?", uint(enum.Admin)).First(&admin).Error; err != nil {
+		return
+	}
+	if err := conn.Model(&admin).Association("Permissions").Append(created); err != nil {
+		fmt.Printf("cannot grant permissions to %s: %v\n", admin.Name, err)
+	}
+}
+
+// backfillEmployeeProfiles gives the default time zone and locale to employees
+// whose columns were left empty, e.g. when added without a default.
+func backfillEmployeeProfiles(conn *gorm.DB) {
+	if err := conn.Model(&model.Employee{}).Where("time_zone IS NULL OR time_zone = ''").Update("time_zone", "UTC").Error; err != nil {
+		fmt.Printf("cannot backfill employee time zones: %v\n", err)
+	}
+	if err := conn.Model(&model.Employee{}).Where("locale IS NULL OR locale = ''").Update("locale", "en").Error; err != nil {
+		fmt.Printf("cannot backfill employee locales: %v\n", err)
+	}
+	if err := conn.Model(&model.Employee{}).Where("status_effective_at IS NULL").Update("status_effective_at", gorm.Expr("created_at")).Error; err != nil {
+		fmt.Printf("cannot backfill employee status dates: %v\n", err)
+	}
+}
@@ -10,6 +10,8 @@ import (
 
 func employeeSeeder(db *gorm.DB) {
 	now := time.Now()
+	employeeNumbers := []string{"EMP001", "EMP002", "EMP003"}
+	managerIDs := []uint{1, 2}
 	var employees = []model.Employee{
 		{
 			Fullname: "Vincent L. Hubbard",
@@ -17,6 +19,8 @@ func employeeSeeder(db *gorm.DB) {
 			Password: "$2a$10$rfpS/jJ.a5J9seBM5sNPTeMQ0iVcAjoox3TDZqLE7omptkVQfaRwW", // 123abcABC!
 			RoleID: 1,
 			DivisionID: 1,
+			JobTitle: "Finance Manager",
+			EmployeeNumber: &employeeNumbers[0],
 			Common: model.Common{ID: 1, CreatedAt: now, UpdatedAt: now},
 		},
 		{
@@ -25,6 +29,9 @@ func employeeSeeder(db *gorm.DB) {
 			Password: "$2a$10$rfpS/jJ.a5J9seBM5sNPTeMQ0iVcAjoox3TDZqLE7omptkVQfaRwW", // 123abcABC!
 			RoleID: 2,
 			DivisionID: 1,
+			JobTitle: "Accountant",
+			EmployeeNumber: &employeeNumbers[1],
+			ManagerID: &managerIDs[0],
 			Common: model.Common{ID: 2, CreatedAt: now, UpdatedAt: now},
 		},
 		{
@@ -33,6 +40,9 @@ func employeeSeeder(db *gorm.DB) {
 			Password: "$2a$10$rfpS/jJ.a5J9seBM5sNPTeMQ0iVcAjoox3TDZqLE7omptkVQfaRwW", // 123abcABC!
 			RoleID: 2,
 			DivisionID: 2,
+			JobTitle: "Software Engineer",
+			EmployeeNumber: &employeeNumbers[2],
+			ManagerID: &managerIDs[1],
 			Common: model.Common{ID: 3, CreatedAt: now, UpdatedAt: now},
 		},
 	}
@@ -0,0 +1,30 @@
+package seeder
+
+import (
+	"log"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"gorm.io/gorm"
+)
+
+func permissionSeeder(db *gorm.DB) {
+	now := time.Now()
+	var permissions []model.Permission
+	for i, p := range enum.Permissions {
+		permissions = append(permissions, model.Permission{
+			Name:        string(p),
+			Description: p.Description(),
+			Common: model.Common{
+				ID:        uint(i + 1),
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+		})
+	}
+	if err := db.Create(&permissions).Error; err != nil {
+		log.Printf("cannot seed data permissions, with error %v\n", err)
+	}
+	log.Println("success seed data permissions")
+}
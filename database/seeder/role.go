@@ -11,9 +11,12 @@ import (
 
 func roleSeeder(db *gorm.DB) {
 	now := time.Now()
+	var permissions []model.Permission
+	db.Find(&permissions)
 	var roles = []model.Role{
 		{
-			Name: enum.Role.String(1),
+			Name:        enum.Role.String(1),
+			Permissions: permissions,
 			Common: model.Common{
 				ID: 1,
 				CreatedAt: now,
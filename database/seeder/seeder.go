@@ -14,13 +14,28 @@ func NewSeeder() *seed {
 }
 
 func (s *seed) SeedAll() {
+	permissionSeeder(s.DB)
 	roleSeeder(s.DB)
 	divisionSeeder(s.DB)
 	employeeSeeder(s.DB)
 }
 
 func (s *seed) DeleteAll() {
+	s.DB.Exec("DELETE FROM impersonation_sessions")
+	s.DB.Exec("DELETE FROM employee_invitations")
+	s.DB.Exec("DELETE FROM employee_status_changes")
+	s.DB.Exec("DELETE FROM employee_pending_changes")
+	s.DB.Exec("DELETE FROM api_keys")
+	s.DB.Exec("DELETE FROM mfa_recovery_codes")
+	s.DB.Exec("DELETE FROM employee_mfas")
+	s.DB.Exec("DELETE FROM password_reset_tokens")
+	s.DB.Exec("DELETE FROM refresh_tokens")
+	s.DB.Exec("UPDATE employees SET manager_id = NULL")
 	s.DB.Exec("DELETE FROM employees")
+	s.DB.Exec("UPDATE divisions SET parent_id = NULL")
 	s.DB.Exec("DELETE FROM divisions")
+	s.DB.Exec("DELETE FROM role_permissions")
+	s.DB.Exec("UPDATE roles SET parent_id = NULL")
 	s.DB.Exec("DELETE FROM roles")
+	s.DB.Exec("DELETE FROM permissions")
 }
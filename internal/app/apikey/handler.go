@@ -0,0 +1,95 @@
+package apikey
+
+import (
+	"net/http"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
+	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+func (h *handler) Get(c echo.Context) error {
+	payload := new(pkgdto.SearchGetRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.Find(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, result.Data, "Get api keys success", &result.PaginationInfo).Send(c)
+}
+
+func (h *handler) GetById(c echo.Context) error {
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindByID(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) Create(c echo.Context) error {
+	principal, err := middleware.Principal(c)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.CreateAPIKeyRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.Store(c.Request().Context(), principal.EmployeeID, payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) Revoke(c echo.Context) error {
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.Revoke(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
@@ -0,0 +1,144 @@
+package apikey
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/mocks"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	adminClaims       = util.CreateJWTClaims(testEmail, testEmployeeID, testAdminRoleID, testDivisionID, enum.PermissionNames()...)
+	db                = database.GetConnection()
+	apiKeyHandler     = NewHandler(&f)
+	echoMock          = mocks.EchoMock{E: echo.New()}
+	f                 = factory.Factory{APIKeyRepository: repository.NewAPIKeyRepository(db)}
+	testAdminRoleID   = uint(enum.Admin)
+	testCreatePayload = dto.CreateAPIKeyRequestBody{Name: "booking-service", Scopes: []string{string(enum.EmployeesRead)}}
+	testDivisionID    = uint(enum.Finance)
+	testEmail         = "vincentlhubbard@superrito.com"
+	testEmployeeID    = uint(1)
+	testUserRoleID    = uint(enum.User)
+	userClaims        = util.CreateJWTClaims(testEmail, testEmployeeID, testUserRoleID, testDivisionID)
+)
+
+func TestAPIKeyHandlerCreateUnauthorized(t *testing.T) {
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(testCreatePayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	c.SetPath("/api/v1/api-keys")
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(middleware.RequirePermission(enum.APIKeysManage)(apiKeyHandler.Create)(c)) {
+		asserts.Equal(401, rec.Code)
+		asserts.Contains(rec.Body.String(), "unauthorized")
+	}
+}
+
+func TestAPIKeyHandlerCreateInvalidPayload(t *testing.T) {
+	token, err := util.CreateJWTToken(adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(dto.CreateAPIKeyRequestBody{Name: "booking-service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	c.SetPath("/api/v1/api-keys")
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(middleware.RequirePermission(enum.APIKeysManage)(apiKeyHandler.Create)(c)) {
+		asserts.Equal(400, rec.Code)
+		asserts.Contains(rec.Body.String(), "Invalid parameters or payload")
+	}
+}
+
+func TestAPIKeyHandlerCreateSuccess(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	token, err := util.CreateJWTToken(adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(testCreatePayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	c.SetPath("/api/v1/api-keys")
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(middleware.RequirePermission(enum.APIKeysManage)(apiKeyHandler.Create)(c)) {
+		asserts.Equal(200, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, `"key":"esk_`)
+		asserts.Contains(body, string(enum.EmployeesRead))
+	}
+}
+
+func TestAPIKeyMiddlewareScope(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	result, err := NewService(&f).Store(ctx, testEmployeeID, &testCreatePayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+	asserts := assert.New(t)
+
+	// granted scope
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.Request().Header.Set(middleware.APIKeyHeader, result.Key)
+	h := middleware.APIKeyMiddleware(f.APIKeyRepository)(middleware.RequireScope(enum.EmployeesRead)(ok))
+	if asserts.NoError(h(c)) {
+		asserts.Equal(204, rec.Code)
+	}
+
+	// missing scope
+	c, rec = echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.Request().Header.Set(middleware.APIKeyHeader, result.Key)
+	h = middleware.APIKeyMiddleware(f.APIKeyRepository)(middleware.RequireScope(enum.DivisionsRead)(ok))
+	if asserts.NoError(h(c)) {
+		asserts.Equal(401, rec.Code)
+	}
+
+	// unknown key
+	c, rec = echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.Request().Header.Set(middleware.APIKeyHeader, "esk_invalid")
+	h = middleware.APIKeyMiddleware(f.APIKeyRepository)(ok)
+	if asserts.NoError(h(c)) {
+		asserts.Equal(401, rec.Code)
+	}
+}
@@ -0,0 +1,15 @@
+package apikey
+
+import (
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handler) Route(g *echo.Group) {
+	g.Use(middleware.JWTMiddleware(), middleware.RequirePermission(enum.APIKeysManage), middleware.DenyImpersonation())
+	g.GET("", h.Get)
+	g.GET("/:id", h.GetById)
+	g.POST("", h.Create)
+	g.DELETE("/:id", h.Revoke)
+}
@@ -0,0 +1,139 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/constant"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
+	pkgutil "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
+	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
+)
+
+// keyPrefix marks the keys issued by this service, which helps secret scanners
+// and people recognise a leaked key.
+const keyPrefix = "esk_"
+
+type service struct {
+	APIKeyRepository repository.APIKey
+}
+
+type Service interface {
+	Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*pkgdto.SearchGetResponse[dto.APIKeyResponse], error)
+	FindByID(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.APIKeyResponse, error)
+	Store(ctx context.Context, createdByID uint, payload *dto.CreateAPIKeyRequestBody) (*dto.APIKeyWithSecretResponse, error)
+	Revoke(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.APIKeyResponse, error)
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		APIKeyRepository: f.APIKeyRepository,
+	}
+}
+
+func (s *service) Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*pkgdto.SearchGetResponse[dto.APIKeyResponse], error) {
+	keys, info, err := s.APIKeyRepository.FindAll(ctx, payload, &payload.Pagination)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	var data []dto.APIKeyResponse
+	for _, key := range keys {
+		data = append(data, toAPIKeyResponse(&key))
+	}
+
+	result := new(pkgdto.SearchGetResponse[dto.APIKeyResponse])
+	result.Data = data
+	result.PaginationInfo = *info
+
+	return result, nil
+}
+
+func (s *service) FindByID(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.APIKeyResponse, error) {
+	data, err := s.APIKeyRepository.FindByID(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return &dto.APIKeyResponse{}, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return &dto.APIKeyResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := toAPIKeyResponse(&data)
+	return &result, nil
+}
+
+// Store creates a key and returns it in clear. This is the only time the key
+// can be read; afterwards only its hash is known.
+func (s *service) Store(ctx context.Context, createdByID uint, payload *dto.CreateAPIKeyRequestBody) (*dto.APIKeyWithSecretResponse, error) {
+	var result *dto.APIKeyWithSecretResponse
+
+	scopes := make([]string, 0, len(payload.Scopes))
+	for _, scope := range payload.Scopes {
+		if !enum.Scope(scope).IsValid() {
+			return result, res.ErrorBuilder(&res.ErrorConstant.Validation, fmt.Errorf("unknown scope %q", scope))
+		}
+		scopes = append(scopes, scope)
+	}
+
+	secret, err := pkgutil.GenerateRandomToken(32)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	key := keyPrefix + secret
+
+	data, err := s.APIKeyRepository.Save(ctx, &model.APIKey{
+		Name:        payload.Name,
+		Prefix:      key[:len(keyPrefix)+8],
+		KeyHash:     pkgutil.HashToken(key),
+		Scopes:      strings.Join(scopes, " "),
+		CreatedByID: createdByID,
+	})
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result = &dto.APIKeyWithSecretResponse{
+		APIKeyResponse: toAPIKeyResponse(data),
+		Key:            key,
+	}
+	return result, nil
+}
+
+func (s *service) Revoke(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.APIKeyResponse, error) {
+	data, err := s.APIKeyRepository.FindByID(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return &dto.APIKeyResponse{}, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return &dto.APIKeyResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if data.RevokedAt != nil {
+		return &dto.APIKeyResponse{}, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("api key already revoked"))
+	}
+
+	if err := s.APIKeyRepository.Revoke(ctx, &data); err != nil {
+		return &dto.APIKeyResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := toAPIKeyResponse(&data)
+	return &result, nil
+}
+
+func toAPIKeyResponse(key *model.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,85 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
+	pkgutil "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ctx           = context.Background()
+	apiKeyService = NewService(factory.NewFactory())
+)
+
+func TestAPIKeyServiceStoreSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	res, err := apiKeyService.Store(ctx, testEmployeeID, &testCreatePayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.NotEmpty(res.ID)
+	asserts.Equal(res.Key[:len(res.Prefix)], res.Prefix)
+	asserts.Equal(testCreatePayload.Scopes, res.Scopes)
+
+	// the key itself is not stored
+	stored, err := f.APIKeyRepository.FindByHash(ctx, pkgutil.HashToken(res.Key))
+	if asserts.NoError(err) {
+		asserts.NotEqual(res.Key, stored.KeyHash)
+	}
+}
+
+func TestAPIKeyServiceStoreUnknownScope(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	_, err := apiKeyService.Store(ctx, testEmployeeID, &dto.CreateAPIKeyRequestBody{Name: "booking-service", Scopes: []string{"employees:write"}})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestAPIKeyServiceRevokeSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	created, err := apiKeyService.Store(ctx, testEmployeeID, &testCreatePayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := apiKeyService.Revoke(ctx, &pkgdto.ByIDRequest{ID: created.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.NotNil(res.RevokedAt)
+
+	_, err = apiKeyService.Revoke(ctx, &pkgdto.ByIDRequest{ID: created.ID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestAPIKeyServiceFindByIdRecordNotFound(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+
+	asserts := assert.New(t)
+	_, err := apiKeyService.FindByID(ctx, &pkgdto.ByIDRequest{ID: 1})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 404")
+	}
+}
@@ -1,8 +1,15 @@
 package auth
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
 
 	"github.com/labstack/echo/v4"
@@ -26,6 +33,7 @@ func (h *handler) LoginByEmailAndPassword(c echo.Context) error {
 	if err := c.Validate(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
 	}
+	payload.IPAddress = c.RealIP()
 
 	employee, err := h.service.LoginByEmailAndPassword(c.Request().Context(), payload)
 	if err != nil {
@@ -52,3 +60,275 @@ func (h *handler) RegisterByEmailAndPassword(c echo.Context) error {
 
 	return res.SuccessResponse(employee).Send(c)
 }
+
+func (h *handler) RefreshToken(c echo.Context) error {
+	payload := new(dto.RefreshTokenRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	employee, err := h.service.RefreshToken(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(employee).Send(c)
+}
+
+func (h *handler) Logout(c echo.Context) error {
+	jwtClaims, err := accessTokenClaims(c)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.LogoutRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+
+	if err := h.service.Logout(c.Request().Context(), jwtClaims, payload); err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, nil, "Logout success", nil).Send(c)
+}
+
+func (h *handler) RevokeSessions(c echo.Context) error {
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	if err := h.service.RevokeSessions(c.Request().Context(), payload); err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, nil, "Revoke sessions success", nil).Send(c)
+}
+
+func (h *handler) ForgotPassword(c echo.Context) error {
+	payload := new(dto.ForgotPasswordRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	if err := h.service.ForgotPassword(c.Request().Context(), payload); err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, nil, "If the email is registered, a reset link has been sent", nil).Send(c)
+}
+
+func (h *handler) ResetPassword(c echo.Context) error {
+	payload := new(dto.ResetPasswordRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	if err := h.service.ResetPassword(c.Request().Context(), payload); err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, nil, "Reset password success", nil).Send(c)
+}
+
+func (h *handler) AcceptInvitation(c echo.Context) error {
+	payload := new(dto.AcceptInvitationRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	employee, err := h.service.AcceptInvitation(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(employee).Send(c)
+}
+
+func (h *handler) UnlockAccount(c echo.Context) error {
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	if err := h.service.UnlockAccount(c.Request().Context(), payload); err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, nil, "Unlock account success", nil).Send(c)
+}
+
+func (h *handler) Impersonate(c echo.Context) error {
+	payload := new(dto.ImpersonateRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.Impersonate(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) GetImpersonations(c echo.Context) error {
+	payload := new(pkgdto.Pagination)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+
+	result, err := h.service.FindImpersonations(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, result.Data, "Get impersonations success", &result.PaginationInfo).Send(c)
+}
+
+func (h *handler) EnrollMFA(c echo.Context) error {
+	jwtClaims, err := enrollmentClaims(c)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	result, err := h.service.EnrollMFA(c.Request().Context(), jwtClaims)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) ConfirmMFA(c echo.Context) error {
+	jwtClaims, err := enrollmentClaims(c)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.MFACodeRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	employee, err := h.service.ConfirmMFA(c.Request().Context(), jwtClaims, payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(employee).Send(c)
+}
+
+func (h *handler) VerifyMFA(c echo.Context) error {
+	payload := new(dto.MFAVerifyRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	employee, err := h.service.VerifyMFA(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(employee).Send(c)
+}
+
+func (h *handler) DisableMFA(c echo.Context) error {
+	jwtClaims, err := accessTokenClaims(c)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.MFACodeRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+
+	if err := h.service.DisableMFA(c.Request().Context(), jwtClaims, payload); err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, nil, "Disable two-factor authentication success", nil).Send(c)
+}
+
+// enrollmentClaims accepts a regular access token as well as the enrollment
+// token returned by a login that requires a second factor.
+func enrollmentClaims(c echo.Context) (*dto.JWTClaims, error) {
+	authHeader := c.Request().Header.Get("Authorization")
+	if jwtClaims, err := util.ParseMFAToken(authHeader, util.MFA_ENROLL_PURPOSE); err == nil {
+		return jwtClaims, nil
+	}
+	return accessTokenClaims(c)
+}
+
+// accessTokenClaims returns the claims of the bearer token of the request,
+// rejecting callers authenticated with an API key.
+func accessTokenClaims(c echo.Context) (*dto.JWTClaims, error) {
+	principal, err := middleware.Principal(c)
+	if err != nil {
+		return nil, err
+	}
+	if principal.Claims == nil {
+		return nil, errors.New("access token required")
+	}
+	return principal.Claims, nil
+}
+
+// JWKS publishes the public keys that verify access tokens, so other services
+// can check tokens without being able to sign them.
+func (h *handler) JWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, util.JWTKeys.JWKS())
+}
+
+// Introspect is restricted to services holding an API key with the
+// tokens:introspect scope.
+func (h *handler) Introspect(c echo.Context) error {
+	principal, err := middleware.Principal(c)
+	if err != nil || !principal.HasScope(enum.TokensIntrospect) {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, errors.New("missing api key with scope "+string(enum.TokensIntrospect))).Send(c)
+	}
+
+	payload := new(dto.IntrospectRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.Introspect(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -10,7 +11,10 @@ import (
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/mocks"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +35,12 @@ func TestAuthHandlerLoginByEmailAndPasswordInvalidPayload(t *testing.T) {
 	// setup handler
 	asserts := assert.New(t)
 	db := database.GetConnection()
-	factory := factory.Factory{EmployeeRepository: repository.NewEmployeeRepository(db)}
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
 	authHandler := NewHandler(&factory)
 	
 	// testing
@@ -67,7 +76,12 @@ func TestAuthHandlerLoginByEmailAndPasswordUnmatchedEmailAndPassword(t *testing.
 	// setup handler
 	asserts := assert.New(t)
 	db := database.GetConnection()
-	factory := factory.Factory{EmployeeRepository: repository.NewEmployeeRepository(db)}
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
 	authHandler := NewHandler(&factory)
 
 	// testing
@@ -87,7 +101,7 @@ func TestAuthHandlerLoginByEmailAndPasswordSuccess(t *testing.T) {
 
 	// setup context
 	emailAndPassword := dto.ByEmailAndPasswordRequest{
-		Email:    "vincentlhubbard@superrito.com",
+		Email:    "devoncthomas@superrito.com",
 		Password: "123abcABC!",
 	}
 	e := echo.New()
@@ -103,7 +117,12 @@ func TestAuthHandlerLoginByEmailAndPasswordSuccess(t *testing.T) {
 	// setup handler
 	asserts := assert.New(t)
 	db := database.GetConnection()
-	factory := factory.Factory{EmployeeRepository: repository.NewEmployeeRepository(db)}
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
 	authHandler := NewHandler(&factory)
 
 	// testing
@@ -150,7 +169,12 @@ func TestAuthHandlerRegisterByEmailAndPasswordUserAlreadyExist(t *testing.T) {
 	// setup handler
 	asserts := assert.New(t)
 	db := database.GetConnection()
-	factory := factory.Factory{EmployeeRepository: repository.NewEmployeeRepository(db)}
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
 	authHandler := NewHandler(&factory)
 
 	// testing
@@ -192,7 +216,12 @@ func TestAuthHandlerRegisterByEmailAndPasswordInvalidPayload(t *testing.T) {
 	// setup handler
 	asserts := assert.New(t)
 	db := database.GetConnection()
-	factory := factory.Factory{EmployeeRepository: repository.NewEmployeeRepository(db)}
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
 	authHandler := NewHandler(&factory)
 
 	// testing
@@ -236,7 +265,12 @@ func TestAuthHandlerRegisterByEmailAndPasswordSuccess(t *testing.T) {
 	// setup handler
 	asserts := assert.New(t)
 	db := database.GetConnection()
-	factory := factory.Factory{EmployeeRepository: repository.NewEmployeeRepository(db)}
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
 	authHandler := NewHandler(&factory)
 
 	// testing
@@ -250,3 +284,350 @@ func TestAuthHandlerRegisterByEmailAndPasswordSuccess(t *testing.T) {
 		asserts.Contains(body, "jwt")
 	}
 }
+
+func TestAuthHandlerRefreshTokenInvalidPayload(t *testing.T) {
+	// setup database
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	// setup context
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", nil)
+	c.SetPath("/api/v1/auth/refresh")
+
+	// setup handler
+	asserts := assert.New(t)
+	db := database.GetConnection()
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
+	authHandler := NewHandler(&factory)
+
+	// testing
+	if asserts.NoError(authHandler.RefreshToken(c)) {
+		asserts.Equal(400, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "Invalid parameters or payload")
+	}
+}
+
+func TestAuthHandlerRefreshTokenUnauthorized(t *testing.T) {
+	// setup database
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	// setup context
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	payload, err := json.Marshal(dto.RefreshTokenRequestBody{RefreshToken: "invalid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.SetPath("/api/v1/auth/refresh")
+
+	// setup handler
+	asserts := assert.New(t)
+	db := database.GetConnection()
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
+	authHandler := NewHandler(&factory)
+
+	// testing
+	if asserts.NoError(authHandler.RefreshToken(c)) {
+		asserts.Equal(401, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
+
+func TestAuthHandlerRevokeSessionsUnauthorized(t *testing.T) {
+	// setup database
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	// setup context
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", nil)
+	token, err := util.CreateJWTToken(util.CreateJWTClaims("devoncthomas@superrito.com", 2, uint(enum.User), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetPath("/api/v1/auth/sessions/:id/revoke")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// setup handler
+	asserts := assert.New(t)
+	db := database.GetConnection()
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
+	authHandler := NewHandler(&factory)
+
+	// testing
+	if asserts.NoError(middleware.RequirePermission(enum.SessionsManage)(authHandler.RevokeSessions)(c)) {
+		asserts.Equal(401, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
+
+func TestAuthHandlerForgotPasswordInvalidPayload(t *testing.T) {
+	// setup context
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	payload, err := json.Marshal(dto.ForgotPasswordRequestBody{Email: "not-an-email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.SetPath("/api/v1/auth/forgot-password")
+
+	// setup handler
+	asserts := assert.New(t)
+	db := database.GetConnection()
+	factory := factory.Factory{
+		EmployeeRepository:           repository.NewEmployeeRepository(db),
+		PasswordResetTokenRepository: repository.NewPasswordResetTokenRepository(db),
+		Notifier:                     &mocks.NotifierMock{},
+	}
+	authHandler := NewHandler(&factory)
+
+	// testing
+	if asserts.NoError(authHandler.ForgotPassword(c)) {
+		asserts.Equal(400, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "Invalid parameters or payload")
+	}
+}
+
+func TestAuthHandlerForgotPasswordSuccess(t *testing.T) {
+	// setup database
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	// setup context
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	payload, err := json.Marshal(dto.ForgotPasswordRequestBody{Email: "vincentlhubbard@superrito.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.SetPath("/api/v1/auth/forgot-password")
+
+	// setup handler
+	asserts := assert.New(t)
+	db := database.GetConnection()
+	notifierMock := &mocks.NotifierMock{}
+	factory := factory.Factory{
+		EmployeeRepository:           repository.NewEmployeeRepository(db),
+		PasswordResetTokenRepository: repository.NewPasswordResetTokenRepository(db),
+		Notifier:                     notifierMock,
+	}
+	authHandler := NewHandler(&factory)
+
+	// testing
+	if asserts.NoError(authHandler.ForgotPassword(c)) {
+		asserts.Equal(200, rec.Code)
+		asserts.Len(notifierMock.Messages, 1)
+	}
+}
+
+func TestAuthHandlerRegisterByEmailAndPasswordWeakPassword(t *testing.T) {
+	// setup database
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	// setup context
+	var (
+		fullname   = "Azka"
+		email      = "azka@superrito.com"
+		password   = "azka"
+		divisionID = uint(2)
+	)
+	emailAndPassword := dto.RegisterEmployeeRequestBody{
+		Fullname:   fullname,
+		Email:      email,
+		Password:   password,
+		DivisionID: &divisionID,
+	}
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	payload, err := json.Marshal(emailAndPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.SetPath("/api/v1/auth/signup")
+
+	// setup handler
+	asserts := assert.New(t)
+	db := database.GetConnection()
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
+	authHandler := NewHandler(&factory)
+
+	// testing
+	if asserts.NoError(authHandler.RegisterByEmailAndPassword(c)) {
+		asserts.Equal(400, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "Invalid parameters or payload")
+		asserts.Contains(body, `"rule":"min_length"`)
+		asserts.Contains(body, `"rule":"personal_info"`)
+	}
+}
+
+func TestAuthHandlerVerifyMFAUnauthorized(t *testing.T) {
+	// setup database
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	// setup context
+	token, err := util.CreateJWTToken(util.CreateJWTClaims("vincentlhubbard@superrito.com", 1, uint(enum.Admin), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	payload, err := json.Marshal(dto.MFAVerifyRequestBody{MFAToken: token, Code: "000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.SetPath("/api/v1/auth/mfa/verify")
+
+	// setup handler
+	asserts := assert.New(t)
+	db := database.GetConnection()
+	factory := factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+		MFARepository:          repository.NewMFARepository(db),
+	}
+	authHandler := NewHandler(&factory)
+
+	// testing: an access token is not accepted in place of an mfa pending token
+	if asserts.NoError(authHandler.VerifyMFA(c)) {
+		asserts.Equal(401, rec.Code)
+	}
+}
+
+func TestAuthHandlerJWKSWithSharedSecret(t *testing.T) {
+	// setup context
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.SetPath("/.well-known/jwks.json")
+
+	// setup handler
+	asserts := assert.New(t)
+	authHandler := NewHandler(&factory.Factory{})
+
+	// testing: the shared secret must never be published
+	if asserts.NoError(authHandler.JWKS(c)) {
+		asserts.Equal(200, rec.Code)
+		asserts.JSONEq(`{"keys": []}`, rec.Body.String())
+	}
+}
+
+func TestAuthHandlerIntrospectUnauthorized(t *testing.T) {
+	// setup context: an employee token is not enough to introspect other tokens
+	token, err := util.CreateJWTToken(util.CreateJWTClaims("vincentlhubbard@superrito.com", 1, uint(enum.Admin), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	payload, err := json.Marshal(dto.IntrospectRequestBody{Token: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	c.SetPath("/api/v1/auth/introspect")
+
+	// setup handler
+	asserts := assert.New(t)
+	authHandler := NewHandler(&factory.Factory{})
+
+	// testing
+	if asserts.NoError(authHandler.Introspect(c)) {
+		asserts.Equal(401, rec.Code)
+	}
+}
+
+func TestAuthHandlerImpersonateUnauthorized(t *testing.T) {
+	// setup database
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	// setup context
+	e := echo.New()
+	echoMock := mocks.EchoMock{E: e}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBufferString(`{"reason": "support ticket"}`))
+	token, err := util.CreateJWTToken(util.CreateJWTClaims("devoncthomas@superrito.com", 2, uint(enum.User), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.SetPath("/api/v1/auth/impersonate/:id")
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// setup handler
+	asserts := assert.New(t)
+	db := database.GetConnection()
+	factory := factory.Factory{
+		EmployeeRepository:      repository.NewEmployeeRepository(db),
+		RoleRepository:          repository.NewRoleRepository(db),
+		ImpersonationRepository: repository.NewImpersonationRepository(db),
+	}
+	authHandler := NewHandler(&factory)
+
+	// testing
+	if asserts.NoError(middleware.RequirePermission(enum.EmployeesImpersonate)(authHandler.Impersonate)(c)) {
+		asserts.Equal(401, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
@@ -1,10 +1,26 @@
 package auth
 
 import (
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	"github.com/labstack/echo/v4"
 )
 
 func (h *handler) Route(g *echo.Group) {
 	g.POST("/login", h.LoginByEmailAndPassword)
 	g.POST("/signup", h.RegisterByEmailAndPassword)
+	g.POST("/refresh", h.RefreshToken)
+	g.POST("/forgot-password", h.ForgotPassword)
+	g.POST("/reset-password", h.ResetPassword)
+	g.POST("/accept-invitation", h.AcceptInvitation)
+	g.POST("/mfa/enroll", h.EnrollMFA, middleware.DenyImpersonation())
+	g.POST("/mfa/confirm", h.ConfirmMFA, middleware.DenyImpersonation())
+	g.POST("/mfa/verify", h.VerifyMFA)
+	g.POST("/introspect", h.Introspect)
+	g.POST("/mfa/disable", h.DisableMFA, middleware.JWTMiddleware(), middleware.DenyImpersonation())
+	g.POST("/logout", h.Logout, middleware.JWTMiddleware())
+	g.POST("/sessions/:id/revoke", h.RevokeSessions, middleware.JWTMiddleware(), middleware.RequirePermission(enum.SessionsManage))
+	g.POST("/accounts/:id/unlock", h.UnlockAccount, middleware.JWTMiddleware(), middleware.RequirePermission(enum.SessionsManage))
+	g.POST("/impersonate/:id", h.Impersonate, middleware.JWTMiddleware(), middleware.RequirePermission(enum.EmployeesImpersonate), middleware.DenyImpersonation())
+	g.GET("/impersonations", h.GetImpersonations, middleware.JWTMiddleware(), middleware.RequirePermission(enum.SessionsManage))
 }
@@ -2,36 +2,711 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/role"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	pkgauth "github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/notifier"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/password"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/throttle"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/totp"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/constant"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	pkgutil "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
 	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
+	"github.com/golang-jwt/jwt/v4"
 )
 
+// dummyPasswordHash is compared against when the email is unknown, so that
+// both failure cases take the same time.
+const dummyPasswordHash = "$2a$10$rfpS/jJ.a5J9seBM5sNPTeMQ0iVcAjoox3TDZqLE7omptkVQfaRwW"
+
+var (
+	PASSWORD_RESET_EXP time.Duration
+	PASSWORD_RESET_URL string
+
+	// REGISTRATION_MODE is "open", "domain" to only let emails of
+	// REGISTRATION_DOMAINS sign up, or "off", the default, to leave creating
+	// employees to admins.
+	REGISTRATION_MODE    string
+	REGISTRATION_DOMAINS map[string]bool
+
+	MFA_ISSUER string
+	// MFA_ENCRYPTION_KEY encrypts the TOTP secrets. It has no default, so
+	// enrolling fails rather than using a key anyone could know.
+	MFA_ENCRYPTION_KEY []byte
+	// MFA_REQUIRED_ROLES lists the roles that cannot sign in without a second factor.
+	MFA_REQUIRED_ROLES map[uint]bool
+)
+
+func init() {
+	// a missing MFA_ENCRYPTION_KEY is reported when main calls LoadConfig
+	_ = LoadConfig()
+}
+
+// LoadConfig reads the settings of the package from the environment. The
+// package loads them when it is initialised, before main has read .env, so
+// main loads them again once it has. It fails without MFA_ENCRYPTION_KEY.
+func LoadConfig() error {
+	PASSWORD_RESET_EXP = pkgutil.GetenvDuration("PASSWORD_RESET_EXP", time.Duration(1)*time.Hour)
+	PASSWORD_RESET_URL = pkgutil.Getenv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password")
+	REGISTRATION_MODE = pkgutil.Getenv("REGISTRATION_MODE", "off")
+	REGISTRATION_DOMAINS = parseDomains(pkgutil.Getenv("REGISTRATION_DOMAINS", ""))
+	MFA_ISSUER = pkgutil.Getenv("MFA_ISSUER", "Employee Service")
+	MFA_REQUIRED_ROLES = parseRoleIDs(pkgutil.Getenv("MFA_REQUIRED_ROLES", "1"))
+
+	MFA_ENCRYPTION_KEY = nil
+	secret := pkgutil.Getenv("MFA_ENCRYPTION_KEY", "")
+	if secret == "" {
+		return errors.New("MFA_ENCRYPTION_KEY is not set")
+	}
+	MFA_ENCRYPTION_KEY = pkgutil.DeriveKey(secret)
+	return nil
+}
+
+const mfaRecoveryCodeCount = 10
+
 type service struct {
-	EmployeeRepository repository.Employee
+	EmployeeRepository           repository.Employee
+	RoleService                  role.Service
+	RefreshTokenRepository       repository.RefreshToken
+	PasswordResetTokenRepository repository.PasswordResetToken
+	MFARepository                repository.MFA
+	APIKeyRepository             repository.APIKey
+	ImpersonationRepository      repository.Impersonation
+	InvitationRepository         repository.Invitation
+	Notifier                     notifier.Notifier
+	PasswordPolicy               password.Policy
+	AccountLimiter               throttle.Limiter
+	IPLimiter                    throttle.Limiter
 }
 
 type Service interface {
 	LoginByEmailAndPassword(ctx context.Context, payload *dto.ByEmailAndPasswordRequest) (*dto.EmployeeWithJWTResponse, error)
 	RegisterByEmailAndPassword(ctx context.Context, payload *dto.RegisterEmployeeRequestBody) (*dto.EmployeeWithJWTResponse, error)
+	RefreshToken(ctx context.Context, payload *dto.RefreshTokenRequestBody) (*dto.EmployeeWithJWTResponse, error)
+	Logout(ctx context.Context, claims *dto.JWTClaims, payload *dto.LogoutRequestBody) error
+	RevokeSessions(ctx context.Context, payload *pkgdto.ByIDRequest) error
+	ForgotPassword(ctx context.Context, payload *dto.ForgotPasswordRequestBody) error
+	ResetPassword(ctx context.Context, payload *dto.ResetPasswordRequestBody) error
+	AcceptInvitation(ctx context.Context, payload *dto.AcceptInvitationRequestBody) (*dto.EmployeeWithJWTResponse, error)
+	UnlockAccount(ctx context.Context, payload *pkgdto.ByIDRequest) error
+	EnrollMFA(ctx context.Context, claims *dto.JWTClaims) (*dto.MFAEnrollResponse, error)
+	ConfirmMFA(ctx context.Context, claims *dto.JWTClaims, payload *dto.MFACodeRequestBody) (*dto.EmployeeWithJWTResponse, error)
+	VerifyMFA(ctx context.Context, payload *dto.MFAVerifyRequestBody) (*dto.EmployeeWithJWTResponse, error)
+	DisableMFA(ctx context.Context, claims *dto.JWTClaims, payload *dto.MFACodeRequestBody) error
+	Introspect(ctx context.Context, payload *dto.IntrospectRequestBody) (*dto.IntrospectResponse, error)
+	Impersonate(ctx context.Context, payload *dto.ImpersonateRequestBody) (*dto.ImpersonationResponse, error)
+	FindImpersonations(ctx context.Context, payload *pkgdto.Pagination) (*pkgdto.SearchGetResponse[dto.ImpersonationSessionResponse], error)
 }
 
 func NewService(f *factory.Factory) Service {
 	return &service{
-		EmployeeRepository: f.EmployeeRepository,
+		EmployeeRepository:           f.EmployeeRepository,
+		RoleService:                  role.NewService(f),
+		RefreshTokenRepository:       f.RefreshTokenRepository,
+		PasswordResetTokenRepository: f.PasswordResetTokenRepository,
+		MFARepository:                f.MFARepository,
+		APIKeyRepository:             f.APIKeyRepository,
+		ImpersonationRepository:      f.ImpersonationRepository,
+		InvitationRepository:         f.InvitationRepository,
+		Notifier:                     f.Notifier,
+		PasswordPolicy:               password.NewPolicy(),
+		AccountLimiter:               throttle.NewMemoryLimiter(throttle.AccountConfig()),
+		IPLimiter:                    throttle.NewMemoryLimiter(throttle.IPConfig()),
 	}
 }
 
 func (s *service) LoginByEmailAndPassword(ctx context.Context, payload *dto.ByEmailAndPasswordRequest) (*dto.EmployeeWithJWTResponse, error) {
 	var result *dto.EmployeeWithJWTResponse
 
+	throttles := s.loginThrottles(payload)
+	if err := s.checkLoginThrottle(ctx, throttles); err != nil {
+		return result, err
+	}
+
+	data, err := s.EmployeeRepository.FindByEmail(ctx, &payload.Email)
+	if err != nil && err != constant.RECORD_NOT_FOUND {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	hashedPassword := dummyPasswordHash
+	if data != nil {
+		hashedPassword = data.Password
+	}
+	if !(pkgutil.CompareHashPassword(payload.Password, hashedPassword)) || data == nil {
+		return result, s.registerLoginFailure(ctx, throttles)
+	}
+
+	if err := s.AccountLimiter.Reset(ctx, accountThrottleKey(data.Email)); err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	return s.completeLogin(ctx, data)
+}
+
+// RegisterByEmailAndPassword lets anyone allowed by REGISTRATION_MODE sign up
+// with the User role.
+func (s *service) RegisterByEmailAndPassword(ctx context.Context, payload *dto.RegisterEmployeeRequestBody) (*dto.EmployeeWithJWTResponse, error) {
+	var result *dto.EmployeeWithJWTResponse
+	if err := checkRegistration(payload.Email); err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+
+	isExist, err := s.EmployeeRepository.ExistByEmail(ctx, &payload.Email)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if isExist {
+		return result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("employee already exists"))
+	}
+	if payload.EmployeeNumber != nil {
+		isExist, err := s.EmployeeRepository.ExistByEmployeeNumber(ctx, *payload.EmployeeNumber)
+		if err != nil {
+			return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		if isExist {
+			return result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("employee number already exists"))
+		}
+	}
+	if violations := s.PasswordPolicy.Validate(payload.Password, payload.Email, payload.Fullname); violations != nil {
+		return result, res.ErrorWithDetailsBuilder(&res.ErrorConstant.Validation, violations, violations)
+	}
+
+	hashedPassword, err := pkgutil.HashPassword(payload.Password)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	now := time.Now()
+	employee := model.Employee{
+		Fullname:          payload.Fullname,
+		Email:             payload.Email,
+		Password:          hashedPassword,
+		RoleID:            uint(enum.User),
+		DivisionID:        *payload.DivisionID,
+		Status:            string(enum.EmployeeActive),
+		StatusEffectiveAt: &now,
+	}
+	payload.EmployeeProfileRequestBody.Apply(&employee)
+	data, err := s.EmployeeRepository.Save(ctx, &employee)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	return s.completeLogin(ctx, data)
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token of the same family. Every refresh token is single-use: presenting
+// one that was already used or revoked revokes its whole family.
+func (s *service) RefreshToken(ctx context.Context, payload *dto.RefreshTokenRequestBody) (*dto.EmployeeWithJWTResponse, error) {
+	var result *dto.EmployeeWithJWTResponse
+
+	token, err := s.RefreshTokenRepository.FindByHash(ctx, pkgutil.HashToken(payload.RefreshToken))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, errors.New("invalid refresh token"))
+		}
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	if token.UsedAt != nil || token.RevokedAt != nil {
+		return result, s.revokeReusedFamily(ctx, token)
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, errors.New("refresh token is expired"))
+	}
+
+	isMarked, err := s.RefreshTokenRepository.MarkUsed(ctx, token)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !isMarked {
+		return result, s.revokeReusedFamily(ctx, token)
+	}
+
+	data, err := s.EmployeeRepository.FindByID(ctx, token.EmployeeID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+		}
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	return s.issueTokens(ctx, &data, token.FamilyID)
+}
+
+// Logout revokes the access token used for the request and, when supplied, the
+// refresh token family of the same session.
+func (s *service) Logout(ctx context.Context, claims *dto.JWTClaims, payload *dto.LogoutRequestBody) error {
+	expiresAt := time.Now().Add(util.JWT_EXP)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if claims.ID != "" {
+		if err := util.RevocationStore.RevokeToken(ctx, claims.ID, expiresAt); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+	if claims.Act != nil {
+		if err := s.ImpersonationRepository.EndByTokenID(ctx, claims.ID); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		return nil
+	}
+
+	if payload.RefreshToken == nil {
+		return nil
+	}
+	token, err := s.RefreshTokenRepository.FindByHash(ctx, pkgutil.HashToken(*payload.RefreshToken))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if token.EmployeeID != claims.UserID {
+		return nil
+	}
+	if err := s.RefreshTokenRepository.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return nil
+}
+
+// RevokeSessions invalidates every access and refresh token issued to the employee so far.
+func (s *service) RevokeSessions(ctx context.Context, payload *pkgdto.ByIDRequest) error {
+	isExist, err := s.EmployeeRepository.ExistByID(ctx, payload.ID)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !isExist {
+		return res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("employee not found"))
+	}
+	return s.revokeAllSessions(ctx, payload.ID)
+}
+
+// ForgotPassword emails a single-use reset link to the employee. Unknown emails
+// are ignored silently so the endpoint cannot be used to discover accounts.
+func (s *service) ForgotPassword(ctx context.Context, payload *dto.ForgotPasswordRequestBody) error {
 	data, err := s.EmployeeRepository.FindByEmail(ctx, &payload.Email)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	if err := s.PasswordResetTokenRepository.InvalidateByEmployeeID(ctx, data.ID); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	token, err := pkgutil.GenerateRandomToken(32)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	_, err = s.PasswordResetTokenRepository.Save(ctx, &model.PasswordResetToken{
+		EmployeeID: data.ID,
+		TokenHash:  pkgutil.HashToken(token),
+		ExpiresAt:  time.Now().Add(PASSWORD_RESET_EXP),
+	})
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	message := notifier.Message{
+		To:      data.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password. The link expires in %s.\n\n%s?token=%s\n\nIf you did not request a password reset, you can ignore this email.",
+			data.Fullname,
+			PASSWORD_RESET_EXP,
+			PASSWORD_RESET_URL,
+			token,
+		),
+	}
+	if err := s.Notifier.Send(ctx, message); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a token sent by ForgotPassword and
+// signs the employee out everywhere.
+func (s *service) ResetPassword(ctx context.Context, payload *dto.ResetPasswordRequestBody) error {
+	errInvalidToken := errors.New("invalid or expired reset token")
+
+	token, err := s.PasswordResetTokenRepository.FindByHash(ctx, pkgutil.HashToken(payload.Token))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errInvalidToken)
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errInvalidToken)
+	}
+
+	data, err := s.EmployeeRepository.FindByID(ctx, token.EmployeeID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errInvalidToken)
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	if violations := s.PasswordPolicy.Validate(payload.Password, data.Email, data.Fullname); violations != nil {
+		return res.ErrorWithDetailsBuilder(&res.ErrorConstant.Validation, violations, violations)
+	}
+
+	isMarked, err := s.PasswordResetTokenRepository.MarkUsed(ctx, token)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !isMarked {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errInvalidToken)
+	}
+
+	hashedPassword, err := pkgutil.HashPassword(payload.Password)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if _, err := s.EmployeeRepository.EditPassword(ctx, &data, hashedPassword); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	return s.revokeAllSessions(ctx, data.ID)
+}
+
+// AcceptInvitation sets the first password of an employee created by an admin,
+// using the token of the invitation sent to them, and signs them in.
+func (s *service) AcceptInvitation(ctx context.Context, payload *dto.AcceptInvitationRequestBody) (*dto.EmployeeWithJWTResponse, error) {
+	var result *dto.EmployeeWithJWTResponse
+	errInvalidToken := errors.New("invalid or expired invitation")
+
+	invitation, err := s.InvitationRepository.FindByHash(ctx, pkgutil.HashToken(payload.Token))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errInvalidToken)
+		}
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if invitation.AcceptedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return result, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errInvalidToken)
+	}
+
+	data, err := s.EmployeeRepository.FindByID(ctx, invitation.EmployeeID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errInvalidToken)
+		}
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if data.Status != string(enum.EmployeeInvited) {
+		return result, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errInvalidToken)
+	}
+
+	if violations := s.PasswordPolicy.Validate(payload.Password, data.Email, data.Fullname); violations != nil {
+		return result, res.ErrorWithDetailsBuilder(&res.ErrorConstant.Validation, violations, violations)
+	}
+
+	isMarked, err := s.InvitationRepository.MarkAccepted(ctx, invitation)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !isMarked {
+		return result, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errInvalidToken)
+	}
+
+	hashedPassword, err := pkgutil.HashPassword(payload.Password)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if _, err := s.EmployeeRepository.EditPassword(ctx, &data, hashedPassword); err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	data.Password = hashedPassword
+	_, err = s.EmployeeRepository.EditStatus(ctx, &data, &model.EmployeeStatusChange{
+		EmployeeID:  data.ID,
+		FromStatus:  data.Status,
+		ToStatus:    string(enum.EmployeeActive),
+		EffectiveAt: *invitation.AcceptedAt,
+		Reason:      "invitation accepted",
+		ChangedByID: &data.ID,
+	})
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	return s.completeLogin(ctx, &data)
+}
+
+// UnlockAccount clears the failed login attempts recorded for the employee.
+func (s *service) UnlockAccount(ctx context.Context, payload *pkgdto.ByIDRequest) error {
+	data, err := s.EmployeeRepository.FindByID(ctx, payload.ID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	if err := s.AccountLimiter.Reset(ctx, accountThrottleKey(data.Email)); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return nil
+}
+
+// EnrollMFA generates a new TOTP secret and recovery codes for the employee. The
+// secret only becomes active once ConfirmMFA accepts a code generated from it.
+func (s *service) EnrollMFA(ctx context.Context, claims *dto.JWTClaims) (*dto.MFAEnrollResponse, error) {
+	var result *dto.MFAEnrollResponse
+
+	mfa, err := s.MFARepository.FindByEmployeeID(ctx, claims.UserID)
+	if err != nil && err != constant.RECORD_NOT_FOUND {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if mfa == nil {
+		mfa = &model.EmployeeMFA{EmployeeID: claims.UserID}
+	} else if mfa.EnabledAt != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("two-factor authentication already enabled"))
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	mfa.Secret, err = pkgutil.Encrypt(secret, MFA_ENCRYPTION_KEY)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	mfa.LastUsedStep = 0
+	if _, err := s.MFARepository.Save(ctx, mfa); err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	recoveryCodes := make([]string, 0, mfaRecoveryCodeCount)
+	codeHashes := make([]string, 0, mfaRecoveryCodeCount)
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		recoveryCodes = append(recoveryCodes, code)
+		codeHashes = append(codeHashes, pkgutil.HashToken(normalizeRecoveryCode(code)))
+	}
+	if err := s.MFARepository.ReplaceRecoveryCodes(ctx, claims.UserID, codeHashes); err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result = &dto.MFAEnrollResponse{
+		Secret:        secret,
+		OTPAuthURI:    totp.URI(MFA_ISSUER, claims.Email, secret),
+		RecoveryCodes: recoveryCodes,
+	}
+	return result, nil
+}
+
+// ConfirmMFA enables two-factor authentication and signs the employee in, which
+// completes the login of employees whose role requires a second factor.
+func (s *service) ConfirmMFA(ctx context.Context, claims *dto.JWTClaims, payload *dto.MFACodeRequestBody) (*dto.EmployeeWithJWTResponse, error) {
+	var result *dto.EmployeeWithJWTResponse
+
+	mfa, err := s.MFARepository.FindByEmployeeID(ctx, claims.UserID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("two-factor enrollment not started"))
+		}
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if mfa.EnabledAt != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("two-factor authentication already enabled"))
+	}
+
+	data, err := s.EmployeeRepository.FindByID(ctx, claims.UserID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+		}
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	isValid, err := s.verifyMFACode(ctx, mfa, payload.Code, false)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !isValid {
+		return result, mfaCodeIncorrectError()
+	}
+	if err := s.MFARepository.Enable(ctx, mfa); err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	return s.issueTokens(ctx, &data, "")
+}
+
+// VerifyMFA completes a login paused by LoginByEmailAndPassword, accepting
+// either a TOTP code or one of the recovery codes.
+func (s *service) VerifyMFA(ctx context.Context, payload *dto.MFAVerifyRequestBody) (*dto.EmployeeWithJWTResponse, error) {
+	var result *dto.EmployeeWithJWTResponse
+
+	claims, err := util.ParseMFATokenString(payload.MFAToken, util.MFA_PENDING_PURPOSE)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+
+	throttles := []loginThrottle{{s.AccountLimiter, mfaThrottleKey(claims.UserID)}}
+	if err := s.checkLoginThrottle(ctx, throttles); err != nil {
+		return result, err
+	}
+
+	mfa, err := s.MFARepository.FindByEmployeeID(ctx, claims.UserID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+		}
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if mfa.EnabledAt == nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, errors.New("two-factor authentication not enabled"))
+	}
+
+	isValid, err := s.verifyMFACode(ctx, mfa, payload.Code, true)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !isValid {
+		if _, err := s.AccountLimiter.RegisterFailure(ctx, mfaThrottleKey(claims.UserID)); err != nil {
+			return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		return result, mfaCodeIncorrectError()
+	}
+	if err := s.AccountLimiter.Reset(ctx, mfaThrottleKey(claims.UserID)); err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	data, err := s.EmployeeRepository.FindByID(ctx, claims.UserID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+		}
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	return s.issueTokens(ctx, &data, "")
+}
+
+// DisableMFA removes the second factor, which is refused for roles that require it.
+func (s *service) DisableMFA(ctx context.Context, claims *dto.JWTClaims, payload *dto.MFACodeRequestBody) error {
+	isRequired, err := s.isMFARequired(ctx, claims.RoleID)
+	if err != nil {
+		return err
+	}
+	if isRequired {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("two-factor authentication is mandatory for this role"))
+	}
+
+	mfa, err := s.MFARepository.FindByEmployeeID(ctx, claims.UserID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("two-factor authentication not enabled"))
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if mfa.EnabledAt != nil {
+		isValid, err := s.verifyMFACode(ctx, mfa, payload.Code, true)
+		if err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		if !isValid {
+			return mfaCodeIncorrectError()
+		}
+	}
+
+	if err := s.MFARepository.Destroy(ctx, claims.UserID); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return nil
+}
+
+// Introspect reports whether an access token or an API key is currently valid,
+// as described by RFC 7662. Invalid, expired and revoked tokens are simply
+// reported as inactive.
+func (s *service) Introspect(ctx context.Context, payload *dto.IntrospectRequestBody) (*dto.IntrospectResponse, error) {
+	if claims, err := util.ParseJWTTokenString(payload.Token); err == nil {
+		result := &dto.IntrospectResponse{
+			Active:          true,
+			TokenType:       "access_token",
+			Sub:             strconv.FormatUint(uint64(claims.UserID), 10),
+			UserID:          claims.UserID,
+			Email:           claims.Email,
+			RoleID:          claims.RoleID,
+			DivisionID:      claims.DivisionID,
+			ScopeDivisionID: claims.ScopeDivisionID,
+			Act:             claims.Act,
+			Jti:             claims.ID,
+			Scope:           strings.Join(claims.Permissions, " "),
+		}
+		if claims.ExpiresAt != nil {
+			result.Exp = claims.ExpiresAt.Unix()
+		}
+		if claims.IssuedAt != nil {
+			result.Iat = claims.IssuedAt.Unix()
+		}
+		return result, nil
+	}
+
+	key, err := s.APIKeyRepository.FindByHash(ctx, pkgutil.HashToken(payload.Token))
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return &dto.IntrospectResponse{Active: false}, nil
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if key.RevokedAt != nil {
+		return &dto.IntrospectResponse{Active: false}, nil
+	}
+	return &dto.IntrospectResponse{
+		Active:    true,
+		TokenType: "api_key",
+		Scope:     key.Scopes,
+		ClientID:  key.Name,
+		Iat:       key.CreatedAt.Unix(),
+	}, nil
+}
+
+// Impersonate signs a short-lived access token that lets the caller act as
+// another employee, for support. The token names the caller in its act claim,
+// cannot be refreshed, and is recorded as an impersonation session.
+func (s *service) Impersonate(ctx context.Context, payload *dto.ImpersonateRequestBody) (*dto.ImpersonationResponse, error) {
+	var result *dto.ImpersonationResponse
+
+	principal, ok := pkgauth.PrincipalFrom(ctx)
+	if !ok {
+		return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, pkgauth.ErrUnauthenticated)
+	}
+	if principal.EmployeeID == payload.ID {
+		return result, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("cannot impersonate yourself"))
+	}
+
+	data, err := s.EmployeeRepository.FindByID(ctx, payload.ID, false)
 	if err != nil {
 		if err == constant.RECORD_NOT_FOUND {
 			return result, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
@@ -39,14 +714,25 @@ func (s *service) LoginByEmailAndPassword(ctx context.Context, payload *dto.ByEm
 		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
 
-	if !(pkgutil.CompareHashPassword(payload.Password, data.Password)) {
-		return result, res.ErrorBuilder(
-			&res.ErrorConstant.EmailOrPasswordIncorrect,
-			errors.New(res.ErrorConstant.EmailOrPasswordIncorrect.Response.Meta.Message),
-		)
+	claims, err := s.accessClaims(ctx, &data)
+	if err != nil {
+		return result, err
+	}
+	if err := pkgauth.Authorize(ctx,
+		pkgauth.Permission(enum.EmployeesImpersonate),
+		pkgauth.NotImpersonating(),
+		pkgauth.InDivision(data.DivisionID),
+		pkgauth.Includes(claims.Permissions, claims.ScopeDivisionID),
+	); err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
 	}
 
-	claims := util.CreateJWTClaims(data.Email, data.ID, data.RoleID, data.DivisionID)
+	claims.Act = &dto.Actor{
+		Sub:    strconv.FormatUint(uint64(principal.EmployeeID), 10),
+		UserID: principal.EmployeeID,
+		Email:  principal.Email,
+	}
+	claims.ExpiresAt = jwt.NewNumericDate(claims.IssuedAt.Add(util.IMPERSONATION_TOKEN_EXP))
 	token, err := util.CreateJWTToken(claims)
 	if err != nil {
 		return result, res.ErrorBuilder(
@@ -55,40 +741,298 @@ func (s *service) LoginByEmailAndPassword(ctx context.Context, payload *dto.ByEm
 		)
 	}
 
-	result = &dto.EmployeeWithJWTResponse{
+	_, err = s.ImpersonationRepository.Save(ctx, &model.ImpersonationSession{
+		ActorID:    principal.EmployeeID,
+		EmployeeID: data.ID,
+		TokenID:    claims.ID,
+		Reason:     payload.Reason,
+		ExpiresAt:  claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result = &dto.ImpersonationResponse{
 		EmployeeResponse: dto.EmployeeResponse{
 			ID:       data.ID,
 			Fullname: data.Fullname,
 			Email:    data.Email,
 		},
-		JWT: token,
+		JWT:       token,
+		ExpiresAt: claims.ExpiresAt.Time,
 	}
+	return result, nil
+}
+
+func (s *service) FindImpersonations(ctx context.Context, payload *pkgdto.Pagination) (*pkgdto.SearchGetResponse[dto.ImpersonationSessionResponse], error) {
+	sessions, info, err := s.ImpersonationRepository.FindAll(ctx, payload)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	var data []dto.ImpersonationSessionResponse
+	for _, session := range sessions {
+		data = append(data, dto.ImpersonationSessionResponse{
+			ID:         session.ID,
+			ActorID:    session.ActorID,
+			EmployeeID: session.EmployeeID,
+			Reason:     session.Reason,
+			ExpiresAt:  session.ExpiresAt,
+			EndedAt:    session.EndedAt,
+			CreatedAt:  session.CreatedAt,
+		})
+	}
+
+	result := new(pkgdto.SearchGetResponse[dto.ImpersonationSessionResponse])
+	result.Data = data
+	result.PaginationInfo = *info
 
 	return result, nil
 }
 
-func (s *service) RegisterByEmailAndPassword(ctx context.Context, payload *dto.RegisterEmployeeRequestBody) (*dto.EmployeeWithJWTResponse, error) {
+// completeLogin signs the employee in once the password is verified, unless a
+// second factor is enabled, or required by the role but not enrolled yet. In
+// both cases only a short-lived token for the next step is returned.
+func (s *service) completeLogin(ctx context.Context, data *model.Employee) (*dto.EmployeeWithJWTResponse, error) {
 	var result *dto.EmployeeWithJWTResponse
-	isExist, err := s.EmployeeRepository.ExistByEmail(ctx, &payload.Email)
-	if err != nil {
+	if err := checkCanSignIn(data); err != nil {
+		return result, err
+	}
+
+	mfa, err := s.MFARepository.FindByEmployeeID(ctx, data.ID)
+	if err != nil && err != constant.RECORD_NOT_FOUND {
 		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
-	if isExist {
-		return result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("employee already exists"))
+
+	purpose := ""
+	if mfa != nil && mfa.EnabledAt != nil {
+		purpose = util.MFA_PENDING_PURPOSE
+	} else {
+		isRequired, err := s.isMFARequired(ctx, data.RoleID)
+		if err != nil {
+			return result, err
+		}
+		if isRequired {
+			purpose = util.MFA_ENROLL_PURPOSE
+		}
+	}
+	if purpose == "" {
+		return s.issueTokens(ctx, data, "")
 	}
 
-	hashedPassword, err := pkgutil.HashPassword(payload.Password)
+	token, err := util.CreateMFAToken(data.Email, data.ID, data.RoleID, data.DivisionID, purpose)
 	if err != nil {
-		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		return result, res.ErrorBuilder(
+			&res.ErrorConstant.InternalServerError,
+			errors.New("error when generating token"),
+		)
 	}
-	payload.Password = hashedPassword
 
-	data, err := s.EmployeeRepository.Save(ctx, payload)
+	result = &dto.EmployeeWithJWTResponse{
+		EmployeeResponse: dto.EmployeeResponse{
+			ID:       data.ID,
+			Fullname: data.Fullname,
+			Email:    data.Email,
+		},
+		MFARequired:           true,
+		MFAEnrollmentRequired: purpose == util.MFA_ENROLL_PURPOSE,
+		MFAToken:              token,
+	}
+	return result, nil
+}
+
+// verifyMFACode accepts a TOTP code once per time step and, when allowRecovery
+// is set, consumes a matching recovery code instead.
+func (s *service) verifyMFACode(ctx context.Context, mfa *model.EmployeeMFA, code string, allowRecovery bool) (bool, error) {
+	secret, err := pkgutil.Decrypt(mfa.Secret, MFA_ENCRYPTION_KEY)
 	if err != nil {
-		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		return false, err
+	}
+	step, isValid, err := totp.Validate(secret, strings.ReplaceAll(code, " ", ""), time.Now())
+	if err != nil {
+		return false, err
+	}
+	if isValid {
+		return s.MFARepository.MarkStepUsed(ctx, mfa, step)
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+	return s.MFARepository.UseRecoveryCode(ctx, mfa.EmployeeID, pkgutil.HashToken(normalizeRecoveryCode(code)))
+}
+
+func mfaCodeIncorrectError() error {
+	return res.ErrorBuilder(
+		&res.ErrorConstant.MFACodeIncorrect,
+		errors.New(res.ErrorConstant.MFACodeIncorrect.Response.Meta.Message),
+	)
+}
+
+// isMFARequired reports whether the role, or a role it inherits from, is listed
+// in MFA_REQUIRED_ROLES.
+func (s *service) isMFARequired(ctx context.Context, roleID uint) (bool, error) {
+	lineage, err := s.RoleService.Lineage(ctx, roleID)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range lineage {
+		if MFA_REQUIRED_ROLES[id] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func parseRoleIDs(value string) map[uint]bool {
+	roleIDs := make(map[uint]bool)
+	for _, field := range strings.Split(value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			continue
+		}
+		roleIDs[uint(id)] = true
+	}
+	return roleIDs
+}
+
+// checkCanSignIn rejects employees whose status does not allow signing in,
+// such as suspended or terminated ones.
+func checkCanSignIn(data *model.Employee) error {
+	if !enum.EmployeeStatus(data.Status).CanSignIn() {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, fmt.Errorf("employee is %s", data.Status))
+	}
+	return nil
+}
+
+// checkRegistration rejects self-registration with the email when
+// REGISTRATION_MODE does not allow it. Unknown modes allow nobody.
+func checkRegistration(email string) error {
+	switch REGISTRATION_MODE {
+	case "open":
+		return nil
+	case "domain":
+		at := strings.LastIndex(email, "@")
+		if at >= 0 && REGISTRATION_DOMAINS[strings.ToLower(email[at+1:])] {
+			return nil
+		}
+		return errors.New("registration is not open to this email domain")
+	}
+	return errors.New("registration is disabled")
+}
+
+func parseDomains(value string) map[string]bool {
+	domains := make(map[string]bool)
+	for _, field := range strings.Split(value, ",") {
+		domain := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(field), "@"))
+		if domain != "" {
+			domains[domain] = true
+		}
+	}
+	return domains
+}
+
+// generateRecoveryCode returns 40 random bits formatted as xxxxx-xxxxx for readability.
+func generateRecoveryCode() (string, error) {
+	bytes := make([]byte, 5)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(bytes)
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func mfaThrottleKey(employeeID uint) string {
+	return "mfa:" + strconv.FormatUint(uint64(employeeID), 10)
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+type loginThrottle struct {
+	limiter throttle.Limiter
+	key     string
+}
+
+// loginThrottles pairs the account limiter, always first, with the IP limiter when the IP is known.
+func (s *service) loginThrottles(payload *dto.ByEmailAndPasswordRequest) []loginThrottle {
+	throttles := []loginThrottle{{s.AccountLimiter, accountThrottleKey(payload.Email)}}
+	if payload.IPAddress != "" {
+		throttles = append(throttles, loginThrottle{s.IPLimiter, "ip:" + payload.IPAddress})
+	}
+	return throttles
+}
+
+func (s *service) checkLoginThrottle(ctx context.Context, throttles []loginThrottle) error {
+	var retryAfter time.Duration
+	for _, t := range throttles {
+		wait, err := t.limiter.Allow(ctx, t.key)
+		if err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		if wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		return tooManyAttemptsError(retryAfter)
+	}
+	return nil
+}
+
+func (s *service) registerLoginFailure(ctx context.Context, throttles []loginThrottle) error {
+	for _, t := range throttles {
+		if _, err := t.limiter.RegisterFailure(ctx, t.key); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+	return res.ErrorBuilder(
+		&res.ErrorConstant.EmailOrPasswordIncorrect,
+		errors.New(res.ErrorConstant.EmailOrPasswordIncorrect.Response.Meta.Message),
+	)
+}
+
+func tooManyAttemptsError(retryAfter time.Duration) error {
+	return res.ErrorWithDetailsBuilder(
+		&res.ErrorConstant.TooManyRequests,
+		errors.New("login temporarily blocked"),
+		map[string]int{"retry_after": int(math.Ceil(retryAfter.Seconds()))},
+	)
+}
+
+func (s *service) revokeAllSessions(ctx context.Context, employeeID uint) error {
+	if err := util.RevocationStore.RevokeEmployee(ctx, employeeID, time.Now()); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
+	if err := s.RefreshTokenRepository.RevokeByEmployeeID(ctx, employeeID); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return nil
+}
+
+func (s *service) revokeReusedFamily(ctx context.Context, token *model.RefreshToken) error {
+	if err := s.RefreshTokenRepository.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, errors.New("refresh token reuse detected"))
+}
 
-	claims := util.CreateJWTClaims(data.Email, data.ID, data.RoleID, data.DivisionID)
+// issueTokens signs an access token for the employee and persists a new refresh
+// token. An empty familyID starts a new token family, i.e. a new session.
+func (s *service) issueTokens(ctx context.Context, data *model.Employee, familyID string) (*dto.EmployeeWithJWTResponse, error) {
+	var result *dto.EmployeeWithJWTResponse
+	if err := checkCanSignIn(data); err != nil {
+		return result, err
+	}
+
+	claims, err := s.accessClaims(ctx, data)
+	if err != nil {
+		return result, err
+	}
 	token, err := util.CreateJWTToken(claims)
 	if err != nil {
 		return result, res.ErrorBuilder(
@@ -97,14 +1041,48 @@ func (s *service) RegisterByEmailAndPassword(ctx context.Context, payload *dto.R
 		)
 	}
 
+	if familyID == "" {
+		familyID, err = pkgutil.GenerateRandomToken(16)
+		if err != nil {
+			return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+	refreshToken, err := pkgutil.GenerateRandomToken(32)
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	_, err = s.RefreshTokenRepository.Save(ctx, &model.RefreshToken{
+		EmployeeID: data.ID,
+		TokenHash:  pkgutil.HashToken(refreshToken),
+		FamilyID:   familyID,
+		ExpiresAt:  time.Now().Add(util.REFRESH_TOKEN_EXP),
+	})
+	if err != nil {
+		return result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
 	result = &dto.EmployeeWithJWTResponse{
 		EmployeeResponse: dto.EmployeeResponse{
 			ID:       data.ID,
 			Fullname: data.Fullname,
 			Email:    data.Email,
 		},
-		JWT: token,
+		JWT:          token,
+		RefreshToken: refreshToken,
 	}
 
 	return result, nil
 }
+
+// accessClaims returns the claims of a new access token for the employee,
+// granting the permissions currently held or inherited by its role.
+func (s *service) accessClaims(ctx context.Context, data *model.Employee) (dto.JWTClaims, error) {
+	names, err := s.RoleService.ResolvePermissions(ctx, data.RoleID)
+	if err != nil {
+		return dto.JWTClaims{}, err
+	}
+
+	claims := util.CreateJWTClaims(data.Email, data.ID, data.RoleID, data.DivisionID, names...)
+	claims.ScopeDivisionID = data.ScopeDivisionID
+	return claims, nil
+}
@@ -4,14 +4,32 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/mocks"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	pkgauth "github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/totp"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
+	pkgutil "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
+func init() {
+	// main refuses to start without a key, tests use a throwaway one
+	if MFA_ENCRYPTION_KEY == nil {
+		MFA_ENCRYPTION_KEY = pkgutil.DeriveKey("testsecret")
+	}
+	// most tests sign employees up themselves
+	REGISTRATION_MODE = "open"
+}
+
 func TestAuthServiceLoginByEmailAndPasswordSuccess(t *testing.T) {
 	database.GetConnection()
 	seeder.NewSeeder().DeleteAll()
@@ -21,7 +39,7 @@ func TestAuthServiceLoginByEmailAndPasswordSuccess(t *testing.T) {
 		authService = NewService(factory.NewFactory())
 		ctx     = context.Background()
 		payload = dto.ByEmailAndPasswordRequest{
-			Email:    "vincentlhubbard@superrito.com",
+			Email:    "devoncthomas@superrito.com",
 			Password: "123abcABC!",
 		}
 	)
@@ -33,6 +51,42 @@ func TestAuthServiceLoginByEmailAndPasswordSuccess(t *testing.T) {
 	asserts.Len(strings.Split(res.JWT, "."), 3)
 }
 
+func TestAuthServiceLoginByEmailAndPasswordSuspended(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		f           = factory.NewFactory()
+		authService = NewService(f)
+		ctx         = context.Background()
+		adminID     = uint(1)
+		payload     = dto.ByEmailAndPasswordRequest{
+			Email:    "devoncthomas@superrito.com",
+			Password: "123abcABC!",
+		}
+	)
+	employee, err := f.EmployeeRepository.FindByID(ctx, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.EmployeeRepository.EditStatus(ctx, &employee, &model.EmployeeStatusChange{
+		EmployeeID:  employee.ID,
+		FromStatus:  employee.Status,
+		ToStatus:    string(enum.EmployeeSuspended),
+		EffectiveAt: time.Now(),
+		ChangedByID: &adminID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = authService.LoginByEmailAndPassword(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
 func TestAuthServiceLoginByEmailAndPasswordRecordNotFound(t *testing.T) {
 	database.GetConnection()
 	seeder.NewSeeder().DeleteAll()
@@ -48,7 +102,7 @@ func TestAuthServiceLoginByEmailAndPasswordRecordNotFound(t *testing.T) {
 	)
 	_, err := authService.LoginByEmailAndPassword(ctx, &payload)
 	if asserts.Error(err) {
-		asserts.Equal(err.Error(), "error code 404")
+		asserts.Equal(err.Error(), "error code 400")
 	}
 }
 
@@ -119,3 +173,674 @@ func TestAuthServiceRegisterByEmailAndPasswordUserExist(t *testing.T) {
 		asserts.Equal(err.Error(), "error code 409")
 	}
 }
+
+func TestAuthServiceRefreshTokenSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	asserts := assert.New(t)
+	var (
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		payload     = dto.ByEmailAndPasswordRequest{
+			Email:    "devoncthomas@superrito.com",
+			Password: "123abcABC!",
+		}
+	)
+	login, err := authService.LoginByEmailAndPassword(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := authService.RefreshToken(ctx, &dto.RefreshTokenRequestBody{RefreshToken: login.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(payload.Email, res.Email)
+	asserts.Len(strings.Split(res.JWT, "."), 3)
+	asserts.NotEmpty(res.RefreshToken)
+	asserts.NotEqual(login.RefreshToken, res.RefreshToken)
+}
+
+func TestAuthServiceRefreshTokenInvalidToken(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+	)
+	_, err := authService.RefreshToken(ctx, &dto.RefreshTokenRequestBody{RefreshToken: "invalid"})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestAuthServiceRefreshTokenReuseRevokesFamily(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		payload     = dto.ByEmailAndPasswordRequest{
+			Email:    "devoncthomas@superrito.com",
+			Password: "123abcABC!",
+		}
+	)
+	login, err := authService.LoginByEmailAndPassword(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := authService.RefreshToken(ctx, &dto.RefreshTokenRequestBody{RefreshToken: login.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reusing the first token must fail and revoke the rotated one as well
+	_, err = authService.RefreshToken(ctx, &dto.RefreshTokenRequestBody{RefreshToken: login.RefreshToken})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+	_, err = authService.RefreshToken(ctx, &dto.RefreshTokenRequestBody{RefreshToken: rotated.RefreshToken})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestAuthServiceLogoutSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		payload     = dto.ByEmailAndPasswordRequest{
+			Email:    "devoncthomas@superrito.com",
+			Password: "123abcABC!",
+		}
+	)
+	login, err := authService.LoginByEmailAndPassword(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := util.ParseJWTTokenString(login.JWT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := authService.Logout(ctx, claims, &dto.LogoutRequestBody{RefreshToken: &login.RefreshToken}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = util.ParseJWTTokenString(login.JWT)
+	asserts.Error(err)
+	_, err = authService.RefreshToken(ctx, &dto.RefreshTokenRequestBody{RefreshToken: login.RefreshToken})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestAuthServiceRevokeSessionsSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		payload     = dto.ByEmailAndPasswordRequest{
+			Email:    "devoncthomas@superrito.com",
+			Password: "123abcABC!",
+		}
+	)
+	login, err := authService.LoginByEmailAndPassword(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := authService.RevokeSessions(ctx, &pkgdto.ByIDRequest{ID: login.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = util.ParseJWTTokenString(login.JWT)
+	asserts.Error(err)
+	_, err = authService.RefreshToken(ctx, &dto.RefreshTokenRequestBody{RefreshToken: login.RefreshToken})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestAuthServiceRevokeSessionsRecordNotFound(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+	)
+	err := authService.RevokeSessions(ctx, &pkgdto.ByIDRequest{ID: 1})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 404")
+	}
+}
+
+func TestAuthServiceForgotPasswordUnknownEmail(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts      = assert.New(t)
+		f            = factory.NewFactory()
+		notifierMock = &mocks.NotifierMock{}
+		ctx          = context.Background()
+	)
+	f.Notifier = notifierMock
+	authService := NewService(f)
+
+	err := authService.ForgotPassword(ctx, &dto.ForgotPasswordRequestBody{Email: "azkaframadhan@superrito.com"})
+	if asserts.NoError(err) {
+		asserts.Empty(notifierMock.Messages)
+	}
+}
+
+func TestAuthServiceResetPasswordSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts      = assert.New(t)
+		f            = factory.NewFactory()
+		notifierMock = &mocks.NotifierMock{}
+		ctx          = context.Background()
+		email        = "bettinameaster@superrito.com"
+		newPassword  = "456defDEF!"
+	)
+	f.Notifier = notifierMock
+	authService := NewService(f)
+
+	if err := authService.ForgotPassword(ctx, &dto.ForgotPasswordRequestBody{Email: email}); err != nil {
+		t.Fatal(err)
+	}
+	message := notifierMock.Last()
+	asserts.Equal(email, message.To)
+	token := strings.Fields(strings.SplitN(message.Body, "?token=", 2)[1])[0]
+
+	if err := authService.ResetPassword(ctx, &dto.ResetPasswordRequestBody{Token: token, Password: newPassword}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := authService.LoginByEmailAndPassword(ctx, &dto.ByEmailAndPasswordRequest{Email: email, Password: newPassword})
+	asserts.NoError(err)
+
+	// the token is single-use
+	err = authService.ResetPassword(ctx, &dto.ResetPasswordRequestBody{Token: token, Password: newPassword})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestAuthServiceResetPasswordInvalidToken(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+	)
+	err := authService.ResetPassword(ctx, &dto.ResetPasswordRequestBody{Token: "invalid", Password: "456defDEF!"})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestAuthServiceRegisterByEmailAndPasswordWeakPassword(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	asserts := assert.New(t)
+	var (
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		divisionID  = uint(1)
+		payload     = dto.RegisterEmployeeRequestBody{
+			Fullname:   "Azka Fadhli Ramadhan",
+			Email:      "azkaframadhan@superrito.com",
+			Password:   "password",
+			DivisionID: &divisionID,
+		}
+	)
+	payload.FillDefaults()
+	_, err := authService.RegisterByEmailAndPassword(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestAuthServiceLoginByEmailAndPasswordThrottled(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		payload     = dto.ByEmailAndPasswordRequest{
+			Email:     "vincentlhubbard@superrito.com",
+			Password:  "1234567890",
+			IPAddress: "127.0.0.1",
+		}
+	)
+	for i := 0; i < 3; i++ {
+		_, err := authService.LoginByEmailAndPassword(ctx, &payload)
+		if asserts.Error(err) {
+			asserts.Equal(err.Error(), "error code 400")
+		}
+	}
+
+	payload.Password = "123abcABC!"
+	_, err := authService.LoginByEmailAndPassword(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 429")
+	}
+}
+
+func TestAuthServiceUnlockAccountSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		payload     = dto.ByEmailAndPasswordRequest{
+			Email:    "vincentlhubbard@superrito.com",
+			Password: "1234567890",
+		}
+	)
+	for i := 0; i < 3; i++ {
+		if _, err := authService.LoginByEmailAndPassword(ctx, &payload); err == nil {
+			t.Fatal("expected login to fail")
+		}
+	}
+	if err := authService.UnlockAccount(ctx, &pkgdto.ByIDRequest{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	payload.Password = "123abcABC!"
+	_, err := authService.LoginByEmailAndPassword(ctx, &payload)
+	asserts.NoError(err)
+}
+
+func TestAuthServiceLoginByEmailAndPasswordMFAEnrollmentRequired(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		payload     = dto.ByEmailAndPasswordRequest{
+			Email:    "vincentlhubbard@superrito.com",
+			Password: "123abcABC!",
+		}
+	)
+	res, err := authService.LoginByEmailAndPassword(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.True(res.MFARequired)
+	asserts.True(res.MFAEnrollmentRequired)
+	asserts.Empty(res.JWT)
+	asserts.Empty(res.RefreshToken)
+
+	_, err = util.ParseJWTTokenString(res.MFAToken)
+	asserts.Error(err)
+}
+
+func TestAuthServiceMFAEnrollAndVerify(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		payload     = dto.ByEmailAndPasswordRequest{
+			Email:    "vincentlhubbard@superrito.com",
+			Password: "123abcABC!",
+		}
+	)
+	login, err := authService.LoginByEmailAndPassword(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := util.ParseMFATokenString(login.MFAToken, util.MFA_ENROLL_PURPOSE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enrollment, err := authService.EnrollMFA(ctx, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(enrollment.RecoveryCodes, 10)
+	asserts.Contains(enrollment.OTPAuthURI, enrollment.Secret)
+
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmed, err := authService.ConfirmMFA(ctx, claims, &dto.MFACodeRequestBody{Code: code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(strings.Split(confirmed.JWT, "."), 3)
+
+	login, err = authService.LoginByEmailAndPassword(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.True(login.MFARequired)
+	asserts.False(login.MFAEnrollmentRequired)
+
+	// a code cannot be replayed within its time step
+	_, err = authService.VerifyMFA(ctx, &dto.MFAVerifyRequestBody{MFAToken: login.MFAToken, Code: code})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+
+	verified, err := authService.VerifyMFA(ctx, &dto.MFAVerifyRequestBody{MFAToken: login.MFAToken, Code: enrollment.RecoveryCodes[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(strings.Split(verified.JWT, "."), 3)
+	asserts.NotEmpty(verified.RefreshToken)
+
+	// recovery codes are single-use
+	_, err = authService.VerifyMFA(ctx, &dto.MFAVerifyRequestBody{MFAToken: login.MFAToken, Code: enrollment.RecoveryCodes[0]})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestAuthServiceDisableMFAMandatoryForRole(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		claims      = util.CreateJWTClaims("vincentlhubbard@superrito.com", 1, uint(enum.Admin), 1)
+	)
+	err := authService.DisableMFA(ctx, &claims, &dto.MFACodeRequestBody{Code: "000000"})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestAuthServiceIntrospectAccessToken(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		claims      = util.CreateJWTClaims("devoncthomas@superrito.com", 2, uint(enum.User), 1)
+	)
+	token, err := util.CreateJWTToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := authService.Introspect(ctx, &dto.IntrospectRequestBody{Token: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.True(res.Active)
+	asserts.Equal("2", res.Sub)
+	asserts.Equal(uint(enum.User), res.RoleID)
+	asserts.Equal(claims.ExpiresAt.Unix(), res.Exp)
+
+	if err := util.RevocationStore.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		t.Fatal(err)
+	}
+	res, err = authService.Introspect(ctx, &dto.IntrospectRequestBody{Token: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.False(res.Active)
+	asserts.Empty(res.Sub)
+}
+
+func TestAuthServiceIntrospectUnknownToken(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+	)
+	res, err := authService.Introspect(ctx, &dto.IntrospectRequestBody{Token: "esk_unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.False(res.Active)
+}
+
+func TestAuthServiceImpersonateSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		claims      = util.CreateJWTClaims("vincentlhubbard@superrito.com", 1, uint(enum.Admin), 1, enum.PermissionNames()...)
+		ctx         = pkgauth.NewContext(context.Background(), pkgauth.FromClaims(&claims))
+	)
+	res, err := authService.Impersonate(ctx, &dto.ImpersonateRequestBody{ID: 2, Reason: "support ticket"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(uint(2), res.ID)
+
+	impersonated, err := util.ParseJWTTokenString(res.JWT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(uint(2), impersonated.UserID)
+	if asserts.NotNil(impersonated.Act) {
+		asserts.Equal(uint(1), impersonated.Act.UserID)
+		asserts.Equal("1", impersonated.Act.Sub)
+	}
+
+	if err := authService.Logout(ctx, impersonated, &dto.LogoutRequestBody{}); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := authService.FindImpersonations(ctx, &pkgdto.Pagination{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(sessions.Data, 1) {
+		asserts.Equal(uint(1), sessions.Data[0].ActorID)
+		asserts.Equal(uint(2), sessions.Data[0].EmployeeID)
+		asserts.Equal("support ticket", sessions.Data[0].Reason)
+		asserts.NotNil(sessions.Data[0].EndedAt)
+	}
+}
+
+func TestAuthServiceImpersonateSelf(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		claims      = util.CreateJWTClaims("vincentlhubbard@superrito.com", 1, uint(enum.Admin), 1, enum.PermissionNames()...)
+		ctx         = pkgauth.NewContext(context.Background(), pkgauth.FromClaims(&claims))
+	)
+	_, err := authService.Impersonate(ctx, &dto.ImpersonateRequestBody{ID: 1, Reason: "support ticket"})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestAuthServiceImpersonateWhileImpersonating(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		claims      = util.CreateJWTClaims("devoncthomas@superrito.com", 2, uint(enum.Admin), 1, enum.PermissionNames()...)
+	)
+	claims.Act = &dto.Actor{Sub: "1", UserID: 1, Email: "vincentlhubbard@superrito.com"}
+	ctx := pkgauth.NewContext(context.Background(), pkgauth.FromClaims(&claims))
+
+	_, err := authService.Impersonate(ctx, &dto.ImpersonateRequestBody{ID: 3, Reason: "support ticket"})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestAuthServiceImpersonateMorePrivilegedEmployee(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		claims      = util.CreateJWTClaims("devoncthomas@superrito.com", 2, uint(enum.User), 1, string(enum.EmployeesImpersonate))
+		ctx         = pkgauth.NewContext(context.Background(), pkgauth.FromClaims(&claims))
+	)
+	// vincent is an admin, so impersonating him would grant devon every permission
+	_, err := authService.Impersonate(ctx, &dto.ImpersonateRequestBody{ID: 1, Reason: "support ticket"})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestAuthServiceRegisterByEmailAndPasswordDisabled(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		authService = NewService(factory.NewFactory())
+		ctx         = context.Background()
+		divisionID  = uint(1)
+		payload     = dto.RegisterEmployeeRequestBody{
+			Fullname:   "Azka Fadhli Ramadhan",
+			Email:      "azkaframadhan@superrito.com",
+			Password:   "123abcABC!",
+			DivisionID: &divisionID,
+		}
+	)
+	defer func(mode string) { REGISTRATION_MODE = mode }(REGISTRATION_MODE)
+	REGISTRATION_MODE = "off"
+
+	payload.FillDefaults()
+	_, err := authService.RegisterByEmailAndPassword(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestAuthServiceRegisterByEmailAndPasswordDomainRestricted(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		f           = factory.NewFactory()
+		authService = NewService(f)
+		ctx         = context.Background()
+		divisionID  = uint(1)
+		payload     = dto.RegisterEmployeeRequestBody{
+			Fullname:   "Azka Fadhli Ramadhan",
+			Email:      "azkaframadhan@example.com",
+			Password:   "123abcABC!",
+			DivisionID: &divisionID,
+		}
+	)
+	defer func(mode string, domains map[string]bool) {
+		REGISTRATION_MODE, REGISTRATION_DOMAINS = mode, domains
+	}(REGISTRATION_MODE, REGISTRATION_DOMAINS)
+	REGISTRATION_MODE = "domain"
+	REGISTRATION_DOMAINS = parseDomains("superrito.com")
+
+	payload.FillDefaults()
+	_, err := authService.RegisterByEmailAndPassword(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+
+	payload.Email = "azkaframadhan@SuperRito.com"
+	_, err = authService.RegisterByEmailAndPassword(ctx, &payload)
+	asserts.NoError(err)
+
+	data, err := f.EmployeeRepository.FindByEmail(ctx, &payload.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(uint(enum.User), data.RoleID)
+}
+
+func TestAuthServiceAcceptInvitationSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+	var (
+		asserts     = assert.New(t)
+		f           = factory.NewFactory()
+		authService = NewService(f)
+		ctx         = context.Background()
+		token       = "invitationtoken"
+		password    = "456defDEF!"
+		adminID     = uint(1)
+		employee    = model.Employee{Fullname: "Azka Fadhli Ramadhan", Email: "azkaframadhan@superrito.com", RoleID: uint(enum.User), DivisionID: 1, Status: string(enum.EmployeeInvited)}
+	)
+	if _, err := f.EmployeeRepository.Save(ctx, &employee); err != nil {
+		t.Fatal(err)
+	}
+	_, err := f.InvitationRepository.Save(ctx, &model.EmployeeInvitation{
+		EmployeeID:  employee.ID,
+		InvitedByID: &adminID,
+		TokenHash:   pkgutil.HashToken(token),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// invited employees cannot sign in before accepting
+	_, err = authService.LoginByEmailAndPassword(ctx, &dto.ByEmailAndPasswordRequest{Email: employee.Email, Password: password})
+	asserts.Error(err)
+
+	res, err := authService.AcceptInvitation(ctx, &dto.AcceptInvitationRequestBody{Token: token, Password: password})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(employee.ID, res.ID)
+	asserts.Len(strings.Split(res.JWT, "."), 3)
+
+	_, err = authService.LoginByEmailAndPassword(ctx, &dto.ByEmailAndPasswordRequest{Email: employee.Email, Password: password})
+	asserts.NoError(err)
+
+	// the invitation is single-use
+	_, err = authService.AcceptInvitation(ctx, &dto.AcceptInvitationRequestBody{Token: token, Password: password})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestLoadConfigMissingMFAEncryptionKey(t *testing.T) {
+	defer func(key []byte) { MFA_ENCRYPTION_KEY = key }(MFA_ENCRYPTION_KEY)
+	t.Setenv("MFA_ENCRYPTION_KEY", "")
+
+	asserts := assert.New(t)
+	asserts.Error(LoadConfig())
+	asserts.Nil(MFA_ENCRYPTION_KEY)
+}
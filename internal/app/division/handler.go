@@ -5,8 +5,6 @@ import (
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
 	"github.com/labstack/echo/v4"
@@ -23,13 +21,7 @@ func NewHandler(f *factory.Factory) *handler {
 }
 
 func (h *handler) Get(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	_, err := util.ParseJWTToken(authHeader)
-	if err != nil {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
-	}
-
-	payload := new(pkgdto.SearchGetRequest)
+	payload := new(dto.DivisionSearchRequest)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
 	}
@@ -47,12 +39,6 @@ func (h *handler) Get(c echo.Context) error {
 }
 
 func (h *handler) GetById(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	_, err := util.ParseJWTToken(authHeader)
-	if err != nil {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
-	}
-
 	payload := new(pkgdto.ByIDRequest)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
@@ -70,12 +56,6 @@ func (h *handler) GetById(c echo.Context) error {
 }
 
 func (h *handler) UpdateById(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	if (err != nil) || (jwtClaims.RoleID != uint(enum.Admin)) {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
-	}
-
 	payload := new(dto.UpdateDivisionRequestBody)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
@@ -92,12 +72,29 @@ func (h *handler) UpdateById(c echo.Context) error {
 }
 
 func (h *handler) DeleteById(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	if (err != nil) || (jwtClaims.RoleID != uint(enum.Admin)) {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	payload := new(dto.DeleteDivisionRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
 	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	var result *dto.DivisionWithCUDResponse
+	var err error
+	if payload.Hard {
+		result, err = h.service.PurgeById(c.Request().Context(), &payload.ByIDRequest)
+	} else {
+		result, err = h.service.DeleteById(c.Request().Context(), payload)
+	}
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
 
+func (h *handler) Restore(c echo.Context) error {
 	payload := new(pkgdto.ByIDRequest)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
@@ -105,7 +102,8 @@ func (h *handler) DeleteById(c echo.Context) error {
 	if err := c.Validate(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
 	}
-	result, err := h.service.DeleteById(c.Request().Context(), payload)
+
+	result, err := h.service.Restore(c.Request().Context(), payload)
 	if err != nil {
 		return res.ErrorResponse(err).Send(c)
 	}
@@ -114,12 +112,6 @@ func (h *handler) DeleteById(c echo.Context) error {
 }
 
 func (h *handler) Create(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	if (err != nil) || (jwtClaims.RoleID != uint(enum.Admin)) {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
-	}
-	
 	payload := new(dto.CreateDivisionRequestBody)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
@@ -127,7 +119,7 @@ func (h *handler) Create(c echo.Context) error {
 	if err := c.Validate(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
 	}
-	
+
 	division, err := h.service.Store(c.Request().Context(), payload)
 	if err != nil {
 		return res.ErrorResponse(err).Send(c)
@@ -135,3 +127,71 @@ func (h *handler) Create(c echo.Context) error {
 
 	return res.SuccessResponse(division).Send(c)
 }
+
+func (h *handler) GetChildren(c echo.Context) error {
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindChildren(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) GetAncestors(c echo.Context) error {
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindAncestors(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) GetTree(c echo.Context) error {
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindTree(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) GetUsage(c echo.Context) error {
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindUsage(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
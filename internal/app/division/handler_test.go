@@ -12,6 +12,7 @@ import (
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/mocks"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
@@ -21,11 +22,11 @@ import (
 )
 
 var (
-	adminClaims       = util.CreateJWTClaims(testEmail, testEmployeeID, testAdminRoleID, testDivisionID)
+	adminClaims       = util.CreateJWTClaims(testEmail, testEmployeeID, testAdminRoleID, testDivisionID, enum.PermissionNames()...)
 	db                = database.GetConnection()
 	divisionHandler   = NewHandler(&f)
 	echoMock          = mocks.EchoMock{E: echo.New()}
-	f                 = factory.Factory{DivisionRepository: repository.NewDivisionRepository(db)}
+	f                 = factory.Factory{DivisionRepository: repository.NewDivisionRepository(db), EmployeeRepository: repository.NewEmployeeRepository(db)}
 	testAdminRoleID   = uint(enum.Admin)
 	testCreatePayload = dto.CreateDivisionRequestBody{Name: &testDivisionName}
 	testDivisionID    = uint(enum.Finance)
@@ -58,15 +59,15 @@ func TestDivisionHandlerGetInvalidPayload(t *testing.T) {
 	}
 }
 func TestDivisionHandlerGetUnauthorized(t *testing.T) {
-	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c, _ := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.Request().Header.Add("Authorization", "Bearer invalid")
 	c.SetPath("/api/v1/divisions")
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.Get(c)) {
-		asserts.Equal(401, rec.Code)
-		body := rec.Body.String()
-		asserts.Contains(body, "unauthorized")
+	var httpErr *echo.HTTPError
+	if asserts.ErrorAs(middleware.JWTMiddleware()(divisionHandler.Get)(c), &httpErr) {
+		asserts.Equal(http.StatusUnauthorized, httpErr.Code)
 	}
 }
 
@@ -145,7 +146,8 @@ func TestDivisionHandlerGetByIdNotFound(t *testing.T) {
 func TestDivisionHandlerGetByIdUnauthorized(t *testing.T) {
 	seeder.NewSeeder().DeleteAll()
 
-	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c, _ := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.Request().Header.Add("Authorization", "Bearer invalid")
 	divisionID := strconv.Itoa(int(testDivisionID))
 
 	c.SetPath("/api/v1/divisions")
@@ -154,10 +156,9 @@ func TestDivisionHandlerGetByIdUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.GetById(c)) {
-		asserts.Equal(401, rec.Code)
-		body := rec.Body.String()
-		asserts.Contains(body, "unauthorized")
+	var httpErr *echo.HTTPError
+	if asserts.ErrorAs(middleware.JWTMiddleware()(divisionHandler.GetById)(c), &httpErr) {
+		asserts.Equal(http.StatusUnauthorized, httpErr.Code)
 	}
 }
 
@@ -203,7 +204,7 @@ func TestDivisionHandlerUpdateByIdInvalidPayload(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.UpdateById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.UpdateById)(c)) {
 		asserts.Equal(400, rec.Code)
 
 		body := rec.Body.String()
@@ -233,7 +234,7 @@ func TestDivisionHandlerUpdateByIdNotFound(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.UpdateById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.UpdateById)(c)) {
 		asserts.Equal(404, rec.Code)
 
 		body := rec.Body.String()
@@ -258,7 +259,7 @@ func TestDivisionHandlerUpdateByIdUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.UpdateById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.UpdateById)(c)) {
 		asserts.Equal(401, rec.Code)
 		body := rec.Body.String()
 		asserts.Contains(body, "unauthorized")
@@ -288,7 +289,7 @@ func TestDivisionHandlerUpdateByIdSuccess(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.UpdateById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.UpdateById)(c)) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
@@ -312,7 +313,7 @@ func TestDivisionHandlerDeleteByIdInvalidPayload(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.DeleteById)(c)) {
 		asserts.Equal(400, rec.Code)
 
 		body := rec.Body.String()
@@ -337,7 +338,7 @@ func TestDivisionHandlerDeleteByIdNotFound(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.DeleteById)(c)) {
 		asserts.Equal(404, rec.Code)
 
 		body := rec.Body.String()
@@ -363,7 +364,7 @@ func TestDivisionHandlerDeleteByIdUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.DeleteById)(c)) {
 		asserts.Equal(401, rec.Code)
 		body := rec.Body.String()
 		asserts.Contains(body, "unauthorized")
@@ -374,7 +375,7 @@ func TestDivisionHandlerDeleteByIdSuccess(t *testing.T) {
 	seeder.NewSeeder().DeleteAll()
 	seeder.NewSeeder().SeedAll()
 
-	c, rec := echoMock.RequestMock(http.MethodDelete, "/", nil)
+	c, rec := echoMock.RequestMock(http.MethodDelete, fmt.Sprintf("/?reassign_to=%d", enum.IT), nil)
 	divisionID := strconv.Itoa(int(testDivisionID))
 	token, err := util.CreateJWTToken(adminClaims)
 	if err != nil {
@@ -388,7 +389,7 @@ func TestDivisionHandlerDeleteByIdSuccess(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.DeleteById)(c)) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
@@ -416,7 +417,7 @@ func TestDivisionHandlerCreateInvalidPayload(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.Create(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.Create)(c)) {
 		asserts.Equal(400, rec.Code)
 
 		body := rec.Body.String()
@@ -446,7 +447,7 @@ func TestDivisionHandlerCreateDivisionAlreadyExist(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.Create(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.Create)(c)) {
 		asserts.Equal(409, rec.Code)
 
 		body := rec.Body.String()
@@ -473,7 +474,7 @@ func TestDivisionHandlerCreateUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.Create(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.Create)(c)) {
 		asserts.Equal(401, rec.Code)
 
 		body := rec.Body.String()
@@ -501,7 +502,7 @@ func TestDivisionHandlerCreateSuccess(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(divisionHandler.Create(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.DivisionsWrite)(divisionHandler.Create)(c)) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
@@ -509,3 +510,60 @@ func TestDivisionHandlerCreateSuccess(t *testing.T) {
 		asserts.Contains(body, "name")
 	}
 }
+
+func TestDivisionHandlerGetTreeNotFound(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	divisionID := strconv.Itoa(int(testDivisionID))
+	token, err := util.CreateJWTToken(adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/divisions/:id/tree")
+	c.SetParamNames("id")
+	c.SetParamValues(divisionID)
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(divisionHandler.GetTree(c)) {
+		asserts.Equal(404, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "Data not found")
+	}
+}
+
+func TestDivisionHandlerGetChildrenSuccess(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	accountingName := "Accounting"
+	if _, err := NewService(&f).Store(ctx, &dto.CreateDivisionRequestBody{Name: &accountingName, ParentID: &testDivisionID}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	divisionID := strconv.Itoa(int(testDivisionID))
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/divisions/:id/children")
+	c.SetParamNames("id")
+	c.SetParamValues(divisionID)
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(divisionHandler.GetChildren(c)) {
+		asserts.Equal(200, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, accountingName)
+		asserts.Contains(body, `"parent_id":1`)
+	}
+}
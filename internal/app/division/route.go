@@ -1,17 +1,21 @@
 package division
 
 import (
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	"github.com/labstack/echo/v4"
 )
 
 func (h *handler) Route(g *echo.Group) {
-	g.Use(middleware.JWTMiddleware(dto.JWTClaims{}, util.JWT_SECRET))
-	g.GET("", h.Get)
-	g.GET("/:id", h.GetById)
-	g.PUT("/:id", h.UpdateById)
-	g.DELETE("/:id", h.DeleteById)
-	g.POST("", h.Create)
+	g.Use(middleware.JWTMiddleware())
+	g.GET("", h.Get, middleware.RequireScope(enum.DivisionsRead))
+	g.GET("/:id", h.GetById, middleware.RequireScope(enum.DivisionsRead))
+	g.GET("/:id/children", h.GetChildren, middleware.RequireScope(enum.DivisionsRead))
+	g.GET("/:id/ancestors", h.GetAncestors, middleware.RequireScope(enum.DivisionsRead))
+	g.GET("/:id/tree", h.GetTree, middleware.RequireScope(enum.DivisionsRead))
+	g.GET("/:id/usage", h.GetUsage, middleware.RequireScope(enum.DivisionsRead))
+	g.PUT("/:id", h.UpdateById, middleware.RequirePermission(enum.DivisionsWrite))
+	g.DELETE("/:id", h.DeleteById, middleware.RequirePermission(enum.DivisionsWrite), middleware.DenyImpersonation())
+	g.POST("/:id/restore", h.Restore, middleware.RequirePermission(enum.DivisionsWrite), middleware.DenyImpersonation())
+	g.POST("", h.Create, middleware.RequirePermission(enum.DivisionsWrite))
 }
@@ -3,9 +3,15 @@ package division
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/constant"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
@@ -14,23 +20,37 @@ import (
 
 type service struct {
 	DivisionRepository repository.Division
+	EmployeeRepository repository.Employee
 }
 
 type Service interface {
-	Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*pkgdto.SearchGetResponse[dto.DivisionResponse], error)
+	Find(ctx context.Context, payload *dto.DivisionSearchRequest) (*pkgdto.SearchGetResponse[dto.DivisionResponse], error)
 	FindByID(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionResponse, error)
 	Store(ctx context.Context, payload *dto.CreateDivisionRequestBody) (*dto.DivisionResponse, error)
 	UpdateById(ctx context.Context, payload *dto.UpdateDivisionRequestBody) (*dto.DivisionResponse, error)
-	DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionWithCUDResponse, error)
+	DeleteById(ctx context.Context, payload *dto.DeleteDivisionRequest) (*dto.DivisionWithCUDResponse, error)
+	PurgeById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionWithCUDResponse, error)
+	Restore(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionResponse, error)
+	FindChildren(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.DivisionResponse, error)
+	FindAncestors(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.DivisionResponse, error)
+	FindTree(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionTreeResponse, error)
+	FindUsage(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionUsageResponse, error)
 }
 
 func NewService(f *factory.Factory) Service {
 	return &service{
 		DivisionRepository: f.DivisionRepository,
+		EmployeeRepository: f.EmployeeRepository,
 	}
 }
 
-func (s *service) Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*pkgdto.SearchGetResponse[dto.DivisionResponse], error) {
+// Find lists deleted divisions only to callers who may restore them.
+func (s *service) Find(ctx context.Context, payload *dto.DivisionSearchRequest) (*pkgdto.SearchGetResponse[dto.DivisionResponse], error) {
+	if payload.DeletedFilter.Any() {
+		if err := auth.Authorize(ctx, auth.Permission(enum.DivisionsWrite)); err != nil {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+		}
+	}
 	divisions, info, err := s.DivisionRepository.FindAll(ctx, payload, &payload.Pagination)
 	if err != nil {
 		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
@@ -39,10 +59,7 @@ func (s *service) Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*
 	var data []dto.DivisionResponse
 
 	for _, division := range divisions {
-		data = append(data, dto.DivisionResponse{
-			ID:   division.ID,
-			Name: division.Name,
-		})
+		data = append(data, newDivisionResponse(&division))
 
 	}
 
@@ -62,8 +79,7 @@ func (s *service) FindByID(ctx context.Context, payload *pkgdto.ByIDRequest) (*d
 		return &dto.DivisionResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
 
-	result.ID = data.ID
-	result.Name = data.Name
+	result = newDivisionResponse(&data)
 
 	return &result, nil
 }
@@ -77,14 +93,21 @@ func (s *service) Store(ctx context.Context, payload *dto.CreateDivisionRequestB
 	if isExist {
 		return &result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("division already exists"))
 	}
+	if payload.ParentID != nil {
+		if err := s.checkParent(ctx, 0, *payload.ParentID); err != nil {
+			return &result, err
+		}
+	}
 
 	data, err := s.DivisionRepository.Save(ctx, payload)
 	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return &result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
 		return &result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
 
-	result.ID = data.ID
-	result.Name = data.Name
+	result = newDivisionResponse(&data)
 
 	return &result, nil
 }
@@ -97,18 +120,33 @@ func (s *service) UpdateById(ctx context.Context, payload *dto.UpdateDivisionReq
 		}
 		return &dto.DivisionResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
+	if payload.ParentID != nil && *payload.ParentID != 0 {
+		if err := s.checkParent(ctx, division.ID, *payload.ParentID); err != nil {
+			return &dto.DivisionResponse{}, err
+		}
+	}
+	if payload.HeadID != nil && *payload.HeadID != 0 {
+		if err := s.checkHead(ctx, division.ID, *payload.HeadID); err != nil {
+			return &dto.DivisionResponse{}, err
+		}
+	}
 
 	_, err = s.DivisionRepository.Edit(ctx, &division, payload)
 	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return &dto.DivisionResponse{}, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
 		return &dto.DivisionResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
-	var result dto.DivisionResponse
-	result.ID = division.ID
-	result.Name = division.Name
+	result := newDivisionResponse(&division)
 
 	return &result, nil
 }
-func (s *service) DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionWithCUDResponse, error) {
+
+// DeleteById refuses to delete a division that still has employees, scoped
+// employees, subdivisions or pending changes into it unless they are moved to
+// payload.ReassignTo.
+func (s *service) DeleteById(ctx context.Context, payload *dto.DeleteDivisionRequest) (*dto.DivisionWithCUDResponse, error) {
 	division, err := s.DivisionRepository.FindByID(ctx, payload.ID)
 	if err != nil {
 		if err == constant.RECORD_NOT_FOUND {
@@ -116,20 +154,288 @@ func (s *service) DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (
 		}
 		return &dto.DivisionWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
-	_, err = s.DivisionRepository.Destroy(ctx, &division)
+	usage, err := s.DivisionRepository.FindUsage(ctx, division.ID)
 	if err != nil {
 		return &dto.DivisionWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
 
+	switch {
+	case payload.ReassignTo != nil:
+		if err := s.reassignAndDestroy(ctx, &division, *payload.ReassignTo, usage); err != nil {
+			return &dto.DivisionWithCUDResponse{}, err
+		}
+	case usage.Employees > 0 || usage.ScopedEmployees > 0 || usage.Subdivisions > 0 || usage.PendingChanges > 0:
+		return &dto.DivisionWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.Conflict, fmt.Errorf("division has %d employees, %d scoped employees, %d subdivisions and %d pending changes, give reassign_to to move them", usage.Employees, usage.ScopedEmployees, usage.Subdivisions, usage.PendingChanges))
+	default:
+		if _, err := s.DivisionRepository.Destroy(ctx, &division); err != nil {
+			return &dto.DivisionWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+
 	result := &dto.DivisionWithCUDResponse{
-		DivisionResponse: dto.DivisionResponse{
-			ID:   division.ID,
-			Name: division.Name,
-		},
-		CreatedAt: division.CreatedAt,
-		UpdatedAt: division.UpdatedAt,
-		DeletedAt: division.DeletedAt,
+		DivisionResponse: newDivisionResponse(&division),
+		CreatedAt:        division.CreatedAt,
+		UpdatedAt:        division.UpdatedAt,
+		DeletedAt:        division.DeletedAt,
+	}
+
+	return result, nil
+}
+
+// reassignAndDestroy moves the division's employees and subdivisions to the
+// target division and deletes it. Moving employees takes the same rights as
+// assigning them a role, and their tokens are revoked as they carry the old
+// division. The target cannot be nested in the division, as its subdivisions
+// would then end up nested in themselves.
+func (s *service) reassignAndDestroy(ctx context.Context, division *model.Division, targetID uint, usage model.DivisionUsage) error {
+	if targetID == division.ID {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("division cannot be reassigned to itself"))
+	}
+	if usage.Employees > 0 || usage.ScopedEmployees > 0 || usage.PendingChanges > 0 {
+		if err := auth.Authorize(ctx, auth.Permission(enum.EmployeesWrite), auth.Unscoped()); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+		}
+	}
+	if _, err := s.DivisionRepository.FindByID(ctx, targetID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("reassign_to division not found"))
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	ancestors, err := s.DivisionRepository.FindAncestors(ctx, targetID)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.ID == division.ID {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, fmt.Errorf("division %d is nested in division %d", targetID, division.ID))
+		}
+	}
+
+	moved, err := s.DivisionRepository.ReassignAndDestroy(ctx, division, targetID)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
+	for _, id := range moved {
+		if err := util.RevocationStore.RevokeEmployee(ctx, id, time.Now()); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+	return nil
+}
 
+// PurgeById removes the division for good, whether it was soft-deleted before
+// or not. Divisions still referred to by employees or subdivisions are kept.
+func (s *service) PurgeById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionWithCUDResponse, error) {
+	if err := auth.Authorize(ctx, auth.Permission(enum.RecordsPurge), auth.NotImpersonating(), auth.Unscoped()); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	division, err := s.DivisionRepository.FindByIDUnscoped(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := s.DivisionRepository.Purge(ctx, &division); err != nil {
+		if errors.Is(err, repository.ErrInUse) {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Conflict, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := &dto.DivisionWithCUDResponse{
+		DivisionResponse: newDivisionResponse(&division),
+		CreatedAt:        division.CreatedAt,
+		UpdatedAt:        division.UpdatedAt,
+		DeletedAt:        division.DeletedAt,
+	}
 	return result, nil
 }
+
+// Restore undeletes the division, unless its name was taken in the meantime or
+// its parent is still deleted.
+func (s *service) Restore(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionResponse, error) {
+	division, err := s.DivisionRepository.FindDeletedByID(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	isExist, err := s.DivisionRepository.ExistByName(ctx, division.Name)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if isExist {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("division name already exists"))
+	}
+	if division.ParentID != nil {
+		if _, err := s.DivisionRepository.FindByID(ctx, *division.ParentID); err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				return nil, res.ErrorBuilder(&res.ErrorConstant.Conflict, errors.New("parent division is deleted"))
+			}
+			return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+
+	if _, err := s.DivisionRepository.Restore(ctx, &division); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	result := newDivisionResponse(&division)
+	return &result, nil
+}
+
+func (s *service) FindChildren(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.DivisionResponse, error) {
+	if err := s.checkExist(ctx, payload.ID); err != nil {
+		return nil, err
+	}
+	children, err := s.DivisionRepository.FindChildren(ctx, payload.ID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return newDivisionResponses(children), nil
+}
+
+// FindAncestors lists the divisions the division is nested in, the nearest
+// first.
+func (s *service) FindAncestors(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.DivisionResponse, error) {
+	if err := s.checkExist(ctx, payload.ID); err != nil {
+		return nil, err
+	}
+	ancestors, err := s.DivisionRepository.FindAncestors(ctx, payload.ID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return newDivisionResponses(ancestors), nil
+}
+
+// FindTree returns the division with every division below it nested in
+// Children.
+func (s *service) FindTree(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionTreeResponse, error) {
+	division, err := s.DivisionRepository.FindByID(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	descendants, err := s.DivisionRepository.FindDescendants(ctx, payload.ID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	root := &dto.DivisionTreeResponse{DivisionResponse: newDivisionResponse(&division), Children: []*dto.DivisionTreeResponse{}}
+	nodes := map[uint]*dto.DivisionTreeResponse{root.ID: root}
+	// descendants come level by level, so a parent is always placed before
+	// its children
+	for i := range descendants {
+		if _, ok := nodes[descendants[i].ID]; ok || descendants[i].ParentID == nil {
+			continue
+		}
+		parent, ok := nodes[*descendants[i].ParentID]
+		if !ok {
+			continue
+		}
+		node := &dto.DivisionTreeResponse{DivisionResponse: newDivisionResponse(&descendants[i]), Children: []*dto.DivisionTreeResponse{}}
+		parent.Children = append(parent.Children, node)
+		nodes[node.ID] = node
+	}
+
+	return root, nil
+}
+
+// FindUsage counts what refers to the division, which has to be moved or
+// deleted before the division can be.
+func (s *service) FindUsage(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.DivisionUsageResponse, error) {
+	if err := s.checkExist(ctx, payload.ID); err != nil {
+		return nil, err
+	}
+	usage, err := s.DivisionRepository.FindUsage(ctx, payload.ID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return &dto.DivisionUsageResponse{
+		ID:              payload.ID,
+		Employees:       usage.Employees,
+		ScopedEmployees: usage.ScopedEmployees,
+		Subdivisions:    usage.Subdivisions,
+		PendingChanges:  usage.PendingChanges,
+	}, nil
+}
+
+func (s *service) checkExist(ctx context.Context, id uint) error {
+	if _, err := s.DivisionRepository.FindByID(ctx, id); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return nil
+}
+
+// checkParent rejects a parent that does not exist, or that would nest the
+// division below itself. divisionID is 0 for a division not created yet.
+func (s *service) checkParent(ctx context.Context, divisionID, parentID uint) error {
+	if parentID == divisionID {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("division cannot be its own parent"))
+	}
+	if _, err := s.DivisionRepository.FindByID(ctx, parentID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("parent division not found"))
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if divisionID == 0 {
+		return nil
+	}
+	ancestors, err := s.DivisionRepository.FindAncestors(ctx, parentID)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.ID == divisionID {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, fmt.Errorf("division %d is already nested in division %d", parentID, divisionID))
+		}
+	}
+	return nil
+}
+
+// checkHead rejects a head that is not an employee of the division.
+func (s *service) checkHead(ctx context.Context, divisionID, headID uint) error {
+	head, err := s.EmployeeRepository.FindByID(ctx, headID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("head employee not found"))
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if head.DivisionID != divisionID {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, fmt.Errorf("employee %d does not belong to division %d", headID, divisionID))
+	}
+	return nil
+}
+
+func newDivisionResponse(division *model.Division) dto.DivisionResponse {
+	return dto.DivisionResponse{
+		ID:       division.ID,
+		Name:     division.Name,
+		ParentID: division.ParentID,
+
+		HeadID:       division.HeadID,
+		Description:  division.Description,
+		CostCenter:   division.CostCenter,
+		ContactEmail: division.ContactEmail,
+	}
+}
+
+func newDivisionResponses(divisions []model.Division) []dto.DivisionResponse {
+	result := make([]dto.DivisionResponse, 0, len(divisions))
+	for i := range divisions {
+		result = append(result, newDivisionResponse(&divisions[i]))
+	}
+	return result
+}
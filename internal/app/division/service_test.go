@@ -6,7 +6,10 @@ import (
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	"github.com/stretchr/testify/assert"
 )
@@ -14,7 +17,7 @@ import (
 var (
 	ctx                 = context.Background()
 	divisionService     = NewService(factory.NewFactory())
-	testFindAllPayload  = pkgdto.SearchGetRequest{}
+	testFindAllPayload  = dto.DivisionSearchRequest{}
 	testFindByIdPayload = pkgdto.ByIDRequest{ID: 1}
 )
 
@@ -90,19 +93,132 @@ func TestDivisionServiceDeleteByIdSuccess(t *testing.T) {
 	seeder.NewSeeder().SeedAll()
 
 	asserts := assert.New(t)
-	res, err := divisionService.DeleteById(ctx, &testFindByIdPayload)
+	payload := dto.DeleteDivisionRequest{}
+	payload.ID = uint(enum.HR)
+	res, err := divisionService.DeleteById(ctx, &payload)
 	if err != nil {
 		t.Fatal(err)
 	}
 	asserts.NotNil(res.DeletedAt)
 }
 
+func TestDivisionServiceDeleteByIdInUse(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	payload := dto.DeleteDivisionRequest{}
+	payload.ID = uint(enum.Finance)
+	_, err := divisionService.DeleteById(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+
+	usage, err := divisionService.FindUsage(ctx, &payload.ByIDRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(int64(2), usage.Employees)
+	asserts.Zero(usage.Subdivisions)
+}
+
+func TestDivisionServiceDeleteByIdReassign(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		adminCtx   = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+		reassignTo = uint(enum.IT)
+		payload    = dto.DeleteDivisionRequest{ReassignTo: &reassignTo}
+	)
+	payload.ID = uint(enum.Finance)
+	_, err := divisionService.DeleteById(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+
+	res, err := divisionService.DeleteById(adminCtx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.NotNil(res.DeletedAt)
+
+	usage, err := divisionService.FindUsage(ctx, &pkgdto.ByIDRequest{ID: reassignTo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(int64(3), usage.Employees)
+}
+
+func TestDivisionServiceDeleteByIdReassignToItself(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		adminCtx   = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+		reassignTo = uint(enum.Finance)
+		payload    = dto.DeleteDivisionRequest{ReassignTo: &reassignTo}
+	)
+	payload.ID = uint(enum.Finance)
+	_, err := divisionService.DeleteById(adminCtx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestDivisionServiceDeleteByIdReassignSubdivisions(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts     = assert.New(t)
+		adminCtx    = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+		hrID        = uint(enum.HR)
+		itID        = uint(enum.IT)
+		recruitName = "Recruitment"
+	)
+	recruitment, err := divisionService.Store(ctx, &dto.CreateDivisionRequestBody{Name: &recruitName, ParentID: &hrID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := dto.DeleteDivisionRequest{}
+	payload.ID = hrID
+	_, err = divisionService.DeleteById(adminCtx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+
+	// the target cannot be one of the subdivisions
+	payload.ReassignTo = &recruitment.ID
+	_, err = divisionService.DeleteById(adminCtx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+
+	payload.ReassignTo = &itID
+	if _, err := divisionService.DeleteById(adminCtx, &payload); err != nil {
+		t.Fatal(err)
+	}
+	res, err := divisionService.FindByID(ctx, &pkgdto.ByIDRequest{ID: recruitment.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(&itID, res.ParentID)
+}
+
 func TestDivisionServiceDeleteByIdRecordNotFound(t *testing.T) {
 	database.GetConnection()
 	seeder.NewSeeder().DeleteAll()
 	
 	asserts := assert.New(t)
-	_, err := divisionService.DeleteById(ctx, &testFindByIdPayload)
+	_, err := divisionService.DeleteById(ctx, &dto.DeleteDivisionRequest{DeleteRequest: pkgdto.DeleteRequest{ByIDRequest: testFindByIdPayload}})
 	if asserts.Error(err) {
 		asserts.Equal(err.Error(), "error code 404")
 	}
@@ -132,3 +248,236 @@ func TestDivisionServiceCreateDivisionAlreadyExist(t *testing.T) {
 		asserts.Equal(err.Error(), "error code 409")
 	}
 }
+
+func TestDivisionServiceFindTreeSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts        = assert.New(t)
+		accountingName = "Accounting"
+		payrollName    = "Payroll"
+		financeID      = uint(enum.Finance)
+	)
+	accounting, err := divisionService.Store(ctx, &dto.CreateDivisionRequestBody{Name: &accountingName, ParentID: &financeID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payroll, err := divisionService.Store(ctx, &dto.CreateDivisionRequestBody{Name: &payrollName, ParentID: &accounting.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := divisionService.FindTree(ctx, &pkgdto.ByIDRequest{ID: financeID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(tree.Children, 1) {
+		asserts.Equal(accounting.ID, tree.Children[0].ID)
+		if asserts.Len(tree.Children[0].Children, 1) {
+			asserts.Equal(payroll.ID, tree.Children[0].Children[0].ID)
+		}
+	}
+
+	ancestors, err := divisionService.FindAncestors(ctx, &pkgdto.ByIDRequest{ID: payroll.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(ancestors, 2) {
+		asserts.Equal(accounting.ID, ancestors[0].ID)
+		asserts.Equal(financeID, ancestors[1].ID)
+	}
+}
+
+func TestDivisionServiceUpdateByIdCycle(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts        = assert.New(t)
+		accountingName = "Accounting"
+		financeID      = uint(enum.Finance)
+		financeName    = enum.Finance.String()
+	)
+	accounting, err := divisionService.Store(ctx, &dto.CreateDivisionRequestBody{Name: &accountingName, ParentID: &financeID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = divisionService.UpdateById(ctx, &dto.UpdateDivisionRequestBody{ID: &financeID, Name: &financeName, ParentID: &accounting.ID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+	_, err = divisionService.UpdateById(ctx, &dto.UpdateDivisionRequestBody{ID: &financeID, Name: &financeName, ParentID: &financeID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestDivisionServiceCreateDivisionParentNotFound(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+
+	var (
+		asserts  = assert.New(t)
+		parentID = uint(10)
+	)
+	_, err := divisionService.Store(ctx, &dto.CreateDivisionRequestBody{Name: &testDivisionName, ParentID: &parentID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestDivisionServiceUpdateByIdHead(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts      = assert.New(t)
+		financeID    = uint(enum.Finance)
+		financeName  = enum.Finance.String()
+		headID       = uint(1)
+		costCenter   = "CC-100"
+		contactEmail = "finance@superrito.com"
+	)
+	res, err := divisionService.UpdateById(ctx, &dto.UpdateDivisionRequestBody{
+		ID:           &financeID,
+		Name:         &financeName,
+		HeadID:       &headID,
+		CostCenter:   &costCenter,
+		ContactEmail: &contactEmail,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.NotNil(res.HeadID) {
+		asserts.Equal(headID, *res.HeadID)
+	}
+	asserts.Equal(costCenter, res.CostCenter)
+	asserts.Equal(contactEmail, res.ContactEmail)
+}
+
+func TestDivisionServiceUpdateByIdHeadOutsideDivision(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts     = assert.New(t)
+		financeID   = uint(enum.Finance)
+		financeName = enum.Finance.String()
+		headID      = uint(3)
+	)
+	_, err := divisionService.UpdateById(ctx, &dto.UpdateDivisionRequestBody{ID: &financeID, Name: &financeName, HeadID: &headID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestDivisionServiceRestoreSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		name     = "Accounting"
+		adminCtx = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+	)
+	division, err := divisionService.Store(ctx, &dto.CreateDivisionRequestBody{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := pkgdto.ByIDRequest{ID: division.ID}
+	if _, err := divisionService.DeleteById(ctx, &dto.DeleteDivisionRequest{DeleteRequest: pkgdto.DeleteRequest{ByIDRequest: payload}}); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := divisionService.Find(adminCtx, &dto.DivisionSearchRequest{DeletedFilter: pkgdto.DeletedFilter{OnlyDeleted: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(deleted.Data, 1) {
+		asserts.Equal(division.ID, deleted.Data[0].ID)
+	}
+
+	res, err := divisionService.Restore(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(name, res.Name)
+
+	_, err = divisionService.Restore(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 404")
+	}
+}
+
+func TestDivisionServiceCreateDivisionNameOfDeleted(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		name    = "Accounting"
+		newName = "Bookkeeping"
+	)
+	division, err := divisionService.Store(ctx, &dto.CreateDivisionRequestBody{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := pkgdto.ByIDRequest{ID: division.ID}
+	if _, err := divisionService.DeleteById(ctx, &dto.DeleteDivisionRequest{DeleteRequest: pkgdto.DeleteRequest{ByIDRequest: payload}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the name stays taken until the division is purged
+	_, err = divisionService.Store(ctx, &dto.CreateDivisionRequestBody{Name: &name})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+	bookkeeping, err := divisionService.Store(ctx, &dto.CreateDivisionRequestBody{Name: &newName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = divisionService.UpdateById(ctx, &dto.UpdateDivisionRequestBody{ID: &bookkeeping.ID, Name: &name})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+
+	res, err := divisionService.Restore(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(name, res.Name)
+}
+
+func TestDivisionServicePurgeByIdInUse(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		adminCtx = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+	)
+	_, err := divisionService.PurgeById(adminCtx, &pkgdto.ByIDRequest{ID: uint(enum.Finance)})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+
+	res, err := divisionService.PurgeById(adminCtx, &pkgdto.ByIDRequest{ID: uint(enum.HR)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(uint(enum.HR), res.ID)
+
+	_, err = divisionService.Restore(ctx, &pkgdto.ByIDRequest{ID: uint(enum.HR)})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 404")
+	}
+}
@@ -1,13 +1,11 @@
 package employee
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
 
@@ -25,13 +23,11 @@ func NewHandler(f *factory.Factory) *handler {
 }
 
 func (h *handler) Get(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	_, err := util.ParseJWTToken(authHeader)
-	if err != nil {
+	if _, err := middleware.Principal(c); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
 	}
 
-	payload := new(pkgdto.SearchGetRequest)
+	payload := new(dto.EmployeeSearchRequest)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
 	}
@@ -49,9 +45,7 @@ func (h *handler) Get(c echo.Context) error {
 }
 
 func (h *handler) GetById(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	_, err := util.ParseJWTToken(authHeader)
-	if err != nil {
+	if _, err := middleware.Principal(c); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
 	}
 
@@ -71,6 +65,24 @@ func (h *handler) GetById(c echo.Context) error {
 	return res.SuccessResponse(result).Send(c)
 }
 
+func (h *handler) Create(c echo.Context) error {
+	payload := new(dto.CreateEmployeeRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+	payload.FillDefaults()
+
+	result, err := h.service.Store(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
 func (h *handler) UpdateById(c echo.Context) error {
 	payload := new(dto.UpdateEmployeeRequestBody)
 	if err := c.Bind(payload); err != nil {
@@ -79,11 +91,7 @@ func (h *handler) UpdateById(c echo.Context) error {
 	if err := c.Validate(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
 	}
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	isAdminOrSameUser := (jwtClaims.UserID == *payload.ID) || (jwtClaims.RoleID == uint(enum.Admin))
-	log.Println(isAdminOrSameUser)
-	if (err != nil) || !isAdminOrSameUser {
+	if _, err := middleware.Principal(c); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
 	}
 	result, err := h.service.UpdateById(c.Request().Context(), payload)
@@ -95,6 +103,118 @@ func (h *handler) UpdateById(c echo.Context) error {
 }
 
 func (h *handler) DeleteById(c echo.Context) error {
+	payload := new(pkgdto.DeleteRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	deleteById := h.service.DeleteById
+	if payload.Hard {
+		deleteById = h.service.PurgeById
+	}
+	result, err := deleteById(c.Request().Context(), &payload.ByIDRequest)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) Restore(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.Restore(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) AssignRole(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.AssignRoleRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.AssignRole(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) ChangePassword(c echo.Context) error {
+	principal, err := middleware.Principal(c)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.ChangePasswordRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	if err := h.service.ChangePassword(c.Request().Context(), principal.EmployeeID, payload); err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, nil, "Change password success", nil).Send(c)
+}
+
+func (h *handler) ChangeStatus(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.ChangeEmployeeStatusRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.ChangeStatus(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) GetStatusChanges(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
 	payload := new(pkgdto.ByIDRequest)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
@@ -102,15 +222,137 @@ func (h *handler) DeleteById(c echo.Context) error {
 	if err := c.Validate(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
 	}
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	if (err != nil) || (jwtClaims.UserID != payload.ID) || (jwtClaims.RoleID != uint(enum.Admin)) {
+
+	result, err := h.service.FindStatusChanges(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, result, "Get status history success", nil).Send(c)
+}
+
+func (h *handler) SchedulePendingChange(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
 	}
-	result, err := h.service.DeleteById(c.Request().Context(), payload)
+
+	payload := new(dto.SchedulePendingChangeRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.SchedulePendingChange(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
+
+func (h *handler) GetPendingChanges(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.PendingChangeSearchRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindPendingChanges(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, result, "Get pending changes success", nil).Send(c)
+}
+
+func (h *handler) CancelPendingChange(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.PendingChangeRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.CancelPendingChange(c.Request().Context(), payload)
 	if err != nil {
 		return res.ErrorResponse(err).Send(c)
 	}
 
 	return res.SuccessResponse(result).Send(c)
 }
+
+func (h *handler) GetManagerChain(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindManagerChain(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, result, "Get manager chain success", nil).Send(c)
+}
+
+func (h *handler) GetDirectReports(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindDirectReports(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, result, "Get direct reports success", nil).Send(c)
+}
+
+func (h *handler) GetReports(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.EmployeeReportsRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindReports(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, result, "Get reports success", nil).Send(c)
+}
@@ -1,6 +1,8 @@
 package employee
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -8,7 +10,9 @@ import (
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/mocks"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
@@ -18,12 +22,17 @@ import (
 )
 
 var (
-	adminClaims     = util.CreateJWTClaims(testEmail, testEmployeeID, testAdminRoleID, testDivisionID)
+	adminClaims     = util.CreateJWTClaims(testEmail, testEmployeeID, testAdminRoleID, testDivisionID, enum.PermissionNames()...)
 	userClaims      = util.CreateJWTClaims(testEmail, uint(2), uint(enum.User), testDivisionID)
 	db              = database.GetConnection()
 	echoMock        = mocks.EchoMock{E: echo.New()}
 	employeeHandler = NewHandler(&f)
-	f               = factory.Factory{EmployeeRepository: repository.NewEmployeeRepository(db)}
+	f               = factory.Factory{
+		EmployeeRepository:     repository.NewEmployeeRepository(db),
+		RoleRepository:         repository.NewRoleRepository(db),
+		DivisionRepository:     repository.NewDivisionRepository(db),
+		RefreshTokenRepository: repository.NewRefreshTokenRepository(db),
+	}
 	testAdminRoleID = uint(enum.Admin)
 	testDivisionID  = uint(enum.Finance)
 	testEmail       = "vincentlhubbard@superrito.com"
@@ -254,6 +263,35 @@ func TestEmployeeHandlerUpdateByIdUnauthorized(t *testing.T) {
 	}
 }
 
+func TestEmployeeHandlerUpdateByIdChangeRoleUnauthorized(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	payload, err := json.Marshal(map[string]uint{"role_id": uint(enum.Admin)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPut, "/", bytes.NewBuffer(payload))
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees")
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(userClaims.UserID)))
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	c.Request().Header.Set("Content-Type", "application/json")
+
+	// testing: employees cannot promote themselves
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.UpdateById(c)) {
+		asserts.Equal(401, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
+
 func TestEmployeeHandlerUpdateByIdSuccess(t *testing.T) {
 	seeder.NewSeeder().DeleteAll()
 	seeder.NewSeeder().SeedAll()
@@ -283,6 +321,81 @@ func TestEmployeeHandlerUpdateByIdSuccess(t *testing.T) {
 	}
 }
 
+func TestEmployeeHandlerUpdateByIdInvalidProfile(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	payload, err := json.Marshal(map[string]string{"phone": "0812-3456-7890", "time_zone": "Jakarta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPut, "/", bytes.NewBuffer(payload))
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees")
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(userClaims.UserID)))
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	c.Request().Header.Set("Content-Type", "application/json")
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.UpdateById(c)) {
+		asserts.Equal(400, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "bad_request")
+	}
+}
+
+func TestEmployeeHandlerGetReportsInvalidDepth(t *testing.T) {
+	c, rec := echoMock.RequestMock(http.MethodGet, "/?depth=100", nil)
+	token, err := util.CreateJWTToken(adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees/:id/reports")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.GetReports(c)) {
+		asserts.Equal(400, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "bad_request")
+	}
+}
+
+func TestEmployeeHandlerGetManagerChainSuccess(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees/:id/manager-chain")
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.GetManagerChain(c)) {
+		asserts.Equal(200, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "Get manager chain success")
+		asserts.Contains(body, "depth")
+	}
+}
+
 func TestEmployeeHandlerDeleteByIdInvalidPayload(t *testing.T) {
 	c, rec := echoMock.RequestMock(http.MethodDelete, "/", nil)
 	employeeID := "a"
@@ -298,7 +411,7 @@ func TestEmployeeHandlerDeleteByIdInvalidPayload(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(employeeHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.EmployeesDelete)(employeeHandler.DeleteById)(c)) {
 		asserts.Equal(400, rec.Code)
 
 		body := rec.Body.String()
@@ -322,7 +435,7 @@ func TestEmployeeHandlerDeleteByIdNotFound(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(employeeHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.EmployeesDelete)(employeeHandler.DeleteById)(c)) {
 		asserts.Equal(404, rec.Code)
 
 		body := rec.Body.String()
@@ -341,7 +454,7 @@ func TestEmployeeHandlerDeleteByIdUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(employeeHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.EmployeesDelete)(employeeHandler.DeleteById)(c)) {
 		asserts.Equal(401, rec.Code)
 		body := rec.Body.String()
 		asserts.Contains(body, "unauthorized")
@@ -365,7 +478,7 @@ func TestEmployeeHandlerDeleteByIdSuccess(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(employeeHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.EmployeesDelete)(employeeHandler.DeleteById)(c)) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
@@ -375,3 +488,231 @@ func TestEmployeeHandlerDeleteByIdSuccess(t *testing.T) {
 		asserts.Contains(body, "deleted_at")
 	}
 }
+
+func TestEmployeeHandlerChangePasswordUnauthorized(t *testing.T) {
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", nil)
+	c.SetPath("/api/v1/employees/me/password")
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.ChangePassword(c)) {
+		asserts.Equal(401, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
+
+func TestEmployeeHandlerChangePasswordIncorrectCurrentPassword(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	payload, err := json.Marshal(dto.ChangePasswordRequestBody{CurrentPassword: "1234567890", NewPassword: "456defDEF!"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees/me/password")
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.ChangePassword(c)) {
+		asserts.Equal(400, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "Current password is incorrect")
+	}
+}
+
+func TestEmployeeHandlerChangePasswordSuccess(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	payload, err := json.Marshal(dto.ChangePasswordRequestBody{CurrentPassword: "123abcABC!", NewPassword: "456defDEF!"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees/me/password")
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.ChangePassword(c)) {
+		asserts.Equal(200, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "Change password success")
+	}
+}
+
+func TestEmployeeHandlerChangePasswordWhileImpersonating(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	payload, err := json.Marshal(dto.ChangePasswordRequestBody{CurrentPassword: "123abcABC!", NewPassword: "456defDEF!"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	claims := userClaims
+	claims.Act = &dto.Actor{Sub: "1", UserID: 1, Email: "vincentlhubbard@superrito.com"}
+	token, err := util.CreateJWTToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees/me/password")
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(middleware.DenyImpersonation()(employeeHandler.ChangePassword)(c)) {
+		asserts.Equal(401, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
+
+func TestEmployeeHandlerAssignRoleUnauthorized(t *testing.T) {
+	payload, err := json.Marshal(map[string]uint{"role_id": uint(enum.Admin)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPut, "/", bytes.NewBuffer(payload))
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees/:id/role")
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(userClaims.UserID)))
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	c.Request().Header.Set("Content-Type", "application/json")
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(middleware.RequirePermission(enum.EmployeesWrite)(employeeHandler.AssignRole)(c)) {
+		asserts.Equal(401, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
+
+func TestEmployeeHandlerCreateInvalidPayload(t *testing.T) {
+	payload, err := json.Marshal(map[string]interface{}{"fullname": "Azka", "email": "azka", "division_id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	token, err := util.CreateJWTToken(adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees")
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.Create(c)) {
+		asserts.Equal(400, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "bad_request")
+	}
+}
+
+func TestEmployeeHandlerChangeStatusInvalidPayload(t *testing.T) {
+	payload, err := json.Marshal(map[string]interface{}{"status": "retired"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	token, err := util.CreateJWTToken(adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees/:id/status")
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.ChangeStatus(c)) {
+		asserts.Equal(400, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "bad_request")
+	}
+}
+
+func TestEmployeeHandlerSchedulePendingChangeInvalidPayload(t *testing.T) {
+	payload, err := json.Marshal(map[string]interface{}{"division_id": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPost, "/", bytes.NewBuffer(payload))
+	token, err := util.CreateJWTToken(adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees/:id/pending-changes")
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+	c.Request().Header.Set("Content-Type", "application/json")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(employeeHandler.SchedulePendingChange(c)) {
+		asserts.Equal(400, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "bad_request")
+	}
+}
+
+func TestEmployeeHandlerDeleteByIdHardUnauthorized(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	c, rec := echoMock.RequestMock(http.MethodDelete, "/?hard=true", nil)
+	claims := util.CreateJWTClaims(testEmail, testEmployeeID, testAdminRoleID, testDivisionID, string(enum.EmployeesDelete))
+	token, err := util.CreateJWTToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/employees")
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(middleware.RequirePermission(enum.EmployeesDelete)(employeeHandler.DeleteById)(c)) {
+		asserts.Equal(401, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
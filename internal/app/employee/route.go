@@ -1,16 +1,27 @@
 package employee
 
 import (
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	"github.com/labstack/echo/v4"
 )
 
 func (h *handler) Route(g *echo.Group) {
-	g.Use(middleware.JWTMiddleware(dto.JWTClaims{}, util.JWT_SECRET))
-	g.GET("", h.Get)
-	g.GET("/:id", h.GetById)
+	g.Use(middleware.JWTMiddleware())
+	g.GET("", h.Get, middleware.RequireScope(enum.EmployeesRead))
+	g.POST("", h.Create, middleware.RequirePermission(enum.EmployeesWrite), middleware.DenyImpersonation())
+	g.GET("/:id", h.GetById, middleware.RequireScope(enum.EmployeesRead))
+	g.GET("/:id/manager-chain", h.GetManagerChain, middleware.RequireScope(enum.EmployeesRead))
+	g.GET("/:id/direct-reports", h.GetDirectReports, middleware.RequireScope(enum.EmployeesRead))
+	g.GET("/:id/reports", h.GetReports, middleware.RequireScope(enum.EmployeesRead))
 	g.PUT("/:id", h.UpdateById)
-	g.DELETE("/:id", h.DeleteById)
+	g.DELETE("/:id", h.DeleteById, middleware.RequirePermission(enum.EmployeesDelete), middleware.DenyImpersonation())
+	g.POST("/:id/restore", h.Restore, middleware.RequirePermission(enum.EmployeesDelete), middleware.DenyImpersonation())
+	g.PUT("/:id/role", h.AssignRole, middleware.RequirePermission(enum.EmployeesWrite), middleware.DenyImpersonation())
+	g.POST("/:id/status", h.ChangeStatus, middleware.RequirePermission(enum.EmployeesWrite), middleware.DenyImpersonation())
+	g.GET("/:id/status-history", h.GetStatusChanges, middleware.RequireScope(enum.EmployeesRead))
+	g.GET("/:id/pending-changes", h.GetPendingChanges, middleware.RequireScope(enum.EmployeesRead))
+	g.POST("/:id/pending-changes", h.SchedulePendingChange, middleware.RequirePermission(enum.EmployeesWrite), middleware.DenyImpersonation())
+	g.DELETE("/:id/pending-changes/:change_id", h.CancelPendingChange, middleware.RequirePermission(enum.EmployeesWrite), middleware.DenyImpersonation())
+	g.POST("/me/password", h.ChangePassword, middleware.DenyImpersonation())
 }
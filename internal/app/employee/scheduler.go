@@ -0,0 +1,57 @@
+package employee
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/sirupsen/logrus"
+)
+
+var SCHEDULER_INTERVAL time.Duration
+
+// pendingChangeBatchSize bounds the changes applied per run; the rest wait for
+// the next one.
+const pendingChangeBatchSize = 100
+
+// Scheduler applies pending employee changes once they are due. Every instance
+// of the service runs one; each change is applied under a row lock, so
+// instances racing for the same change apply it only once.
+type Scheduler struct {
+	service  *service
+	Interval time.Duration
+}
+
+func NewScheduler(f *factory.Factory) *Scheduler {
+	return &Scheduler{
+		service:  newService(f),
+		Interval: SCHEDULER_INTERVAL,
+	}
+}
+
+// Run applies due changes every Interval until ctx is done. A non-positive
+// Interval disables the scheduler on this instance.
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.ApplyDue(ctx, time.Now()); err != nil {
+			logrus.Error("apply pending employee changes: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ApplyDue applies the changes due by now and returns how many were applied.
+func (s *Scheduler) ApplyDue(ctx context.Context, now time.Time) (int, error) {
+	return s.service.applyDueChanges(ctx, now)
+}
@@ -0,0 +1,265 @@
+package employee
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerApplyDueSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts     = assert.New(t)
+		divisionID  = uint(enum.IT)
+		status      = string(enum.EmployeeOnLeave)
+		effectiveAt = time.Now().Add(time.Hour)
+		scheduler   = NewScheduler(factory.NewFactory())
+	)
+	change, err := testEmployeeService.SchedulePendingChange(adminCtx, &dto.SchedulePendingChangeRequestBody{
+		ID:          2,
+		DivisionID:  &divisionID,
+		Status:      &status,
+		EffectiveAt: effectiveAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(string(enum.PendingChangePending), change.State)
+
+	// not due yet
+	applied, err := scheduler.ApplyDue(ctx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(0, applied)
+
+	applied, err = scheduler.ApplyDue(ctx, effectiveAt.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(1, applied)
+
+	employee, err := testEmployeeService.FindByID(ctx, &pkgdto.ByIDRequest{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(divisionID, employee.Division.ID)
+	asserts.Equal(status, employee.Status)
+
+	history, err := testEmployeeService.FindStatusChanges(adminCtx, &pkgdto.ByIDRequest{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(history, 1)
+
+	pending, err := testEmployeeService.FindPendingChanges(adminCtx, &dto.PendingChangeSearchRequest{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Empty(pending)
+}
+
+func TestSchedulerApplyDueOnce(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts     = assert.New(t)
+		status      = string(enum.EmployeeSuspended)
+		effectiveAt = time.Now().Add(time.Hour)
+	)
+	_, err := testEmployeeService.SchedulePendingChange(adminCtx, &dto.SchedulePendingChangeRequestBody{
+		ID:          2,
+		Status:      &status,
+		EffectiveAt: effectiveAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// two instances picking up the same change
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		applied int
+	)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := NewScheduler(factory.NewFactory()).ApplyDue(ctx, effectiveAt.Add(time.Minute))
+			asserts.NoError(err)
+			mu.Lock()
+			applied += n
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	asserts.Equal(1, applied)
+
+	history, err := testEmployeeService.FindStatusChanges(adminCtx, &pkgdto.ByIDRequest{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(history, 1)
+}
+
+func TestSchedulerApplyDueInvalidTransition(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts     = assert.New(t)
+		status      = string(enum.EmployeeInvited)
+		effectiveAt = time.Now().Add(time.Hour)
+	)
+	_, err := testEmployeeService.SchedulePendingChange(adminCtx, &dto.SchedulePendingChangeRequestBody{
+		ID:          2,
+		Status:      &status,
+		EffectiveAt: effectiveAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := NewScheduler(factory.NewFactory()).ApplyDue(ctx, effectiveAt.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(0, applied)
+
+	failed, err := testEmployeeService.FindPendingChanges(adminCtx, &dto.PendingChangeSearchRequest{ID: 2, State: string(enum.PendingChangeFailed)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(failed, 1) {
+		asserts.Equal("status cannot change from active to invited", failed[0].Error)
+	}
+}
+
+// failingPendingChanges fails to apply the change with the given ID.
+type failingPendingChanges struct {
+	repository.PendingChange
+	id uint
+}
+
+func (r failingPendingChanges) Apply(ctx context.Context, change *model.EmployeePendingChange, employee *model.Employee) (bool, error) {
+	if change.ID == r.id {
+		return false, errors.New("constraint violated")
+	}
+	return r.PendingChange.Apply(ctx, change, employee)
+}
+
+func TestSchedulerApplyDueSkipsFailing(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts     = assert.New(t)
+		f           = factory.NewFactory()
+		scheduler   = NewScheduler(f)
+		status      = string(enum.EmployeeOnLeave)
+		effectiveAt = time.Now().Add(time.Hour)
+	)
+	first, err := testEmployeeService.SchedulePendingChange(adminCtx, &dto.SchedulePendingChangeRequestBody{
+		ID:          2,
+		Status:      &status,
+		EffectiveAt: effectiveAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testEmployeeService.SchedulePendingChange(adminCtx, &dto.SchedulePendingChangeRequestBody{
+		ID:          3,
+		Status:      &status,
+		EffectiveAt: effectiveAt.Add(time.Minute),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	scheduler.service.PendingChangeRepository = failingPendingChanges{PendingChange: f.PendingChangeRepository, id: first.ID}
+
+	applied, err := scheduler.ApplyDue(ctx, effectiveAt.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(1, applied)
+
+	failed, err := testEmployeeService.FindPendingChanges(adminCtx, &dto.PendingChangeSearchRequest{ID: 2, State: string(enum.PendingChangeFailed)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(failed, 1) {
+		asserts.Equal("constraint violated", failed[0].Error)
+	}
+	employee, err := testEmployeeService.FindByID(ctx, &pkgdto.ByIDRequest{ID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(status, employee.Status)
+}
+
+func TestSchedulerApplyDueStatusChangedMeanwhile(t *testing.T) {
+	db := database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts     = assert.New(t)
+		f           = factory.NewFactory()
+		status      = string(enum.EmployeeOnLeave)
+		effectiveAt = time.Now().Add(time.Hour)
+	)
+	scheduled, err := testEmployeeService.SchedulePendingChange(adminCtx, &dto.SchedulePendingChangeRequestBody{
+		ID:          2,
+		Status:      &status,
+		EffectiveAt: effectiveAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, err := f.PendingChangeRepository.FindByID(ctx, scheduled.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	employee, err := f.EmployeeRepository.FindByID(ctx, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// devon is terminated after the scheduler read them
+	if err := db.Model(&model.Employee{}).Where("id = ?", 2).Update("status", enum.EmployeeTerminated).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := f.PendingChangeRepository.Apply(ctx, change, &employee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.False(ok)
+	asserts.Equal(string(enum.PendingChangeFailed), change.State)
+	asserts.Equal("status cannot change from terminated to on_leave", change.Error)
+
+	employee, err = f.EmployeeRepository.FindByID(ctx, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(string(enum.EmployeeTerminated), employee.Status)
+}
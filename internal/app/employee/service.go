@@ -2,34 +2,109 @@ package employee
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/role"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/notifier"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/password"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/constant"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
+	pkgutil "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
 	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
+	"github.com/sirupsen/logrus"
 )
 
+var (
+	INVITATION_EXP time.Duration
+	INVITATION_URL string
+)
+
+func init() {
+	LoadConfig()
+}
+
+// LoadConfig reads the settings of the package from the environment. The
+// package loads them when it is initialised, before main has read .env, so
+// main loads them again once it has.
+func LoadConfig() {
+	INVITATION_EXP = pkgutil.GetenvDuration("INVITATION_EXP", time.Duration(72)*time.Hour)
+	INVITATION_URL = pkgutil.Getenv("INVITATION_URL", "http://localhost:3000/accept-invitation")
+	SCHEDULER_INTERVAL = pkgutil.GetenvDuration("SCHEDULER_INTERVAL", time.Minute)
+}
+
 type service struct {
-	EmployeeRepository repository.Employee
+	EmployeeRepository      repository.Employee
+	RoleRepository          repository.Role
+	RoleService             role.Service
+	DivisionRepository      repository.Division
+	RefreshTokenRepository  repository.RefreshToken
+	InvitationRepository    repository.Invitation
+	PendingChangeRepository repository.PendingChange
+	Notifier                notifier.Notifier
+	PasswordPolicy          password.Policy
 }
 
+// Service authorizes the auth.Principal found in ctx. Division-scoped callers
+// only see and change employees of their division.
 type Service interface {
-	Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*pkgdto.SearchGetResponse[dto.EmployeeResponse], error)
+	Find(ctx context.Context, payload *dto.EmployeeSearchRequest) (*pkgdto.SearchGetResponse[dto.EmployeeResponse], error)
 	FindByID(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.EmployeeDetailResponse, error)
+	Store(ctx context.Context, payload *dto.CreateEmployeeRequestBody) (*dto.InvitedEmployeeResponse, error)
 	UpdateById(ctx context.Context, payload *dto.UpdateEmployeeRequestBody) (*dto.EmployeeDetailResponse, error)
 	DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.EmployeeWithCUDResponse, error)
+	PurgeById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.EmployeeWithCUDResponse, error)
+	Restore(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.EmployeeDetailResponse, error)
+	AssignRole(ctx context.Context, payload *dto.AssignRoleRequestBody) (*dto.EmployeeDetailResponse, error)
+	ChangePassword(ctx context.Context, employeeID uint, payload *dto.ChangePasswordRequestBody) error
+	FindManagerChain(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.EmployeeReportResponse, error)
+	FindDirectReports(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.EmployeeReportResponse, error)
+	FindReports(ctx context.Context, payload *dto.EmployeeReportsRequest) ([]dto.EmployeeReportResponse, error)
+	ChangeStatus(ctx context.Context, payload *dto.ChangeEmployeeStatusRequestBody) (*dto.EmployeeDetailResponse, error)
+	FindStatusChanges(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.EmployeeStatusChangeResponse, error)
+	SchedulePendingChange(ctx context.Context, payload *dto.SchedulePendingChangeRequestBody) (*dto.PendingChangeResponse, error)
+	FindPendingChanges(ctx context.Context, payload *dto.PendingChangeSearchRequest) ([]dto.PendingChangeResponse, error)
+	CancelPendingChange(ctx context.Context, payload *dto.PendingChangeRequest) (*dto.PendingChangeResponse, error)
 }
 
 func NewService(f *factory.Factory) Service {
+	return newService(f)
+}
+
+func newService(f *factory.Factory) *service {
 	return &service{
-		EmployeeRepository: f.EmployeeRepository,
+		EmployeeRepository:      f.EmployeeRepository,
+		RoleRepository:          f.RoleRepository,
+		RoleService:             role.NewService(f),
+		DivisionRepository:      f.DivisionRepository,
+		RefreshTokenRepository:  f.RefreshTokenRepository,
+		InvitationRepository:    f.InvitationRepository,
+		PendingChangeRepository: f.PendingChangeRepository,
+		Notifier:                f.Notifier,
+		PasswordPolicy:          password.NewPolicy(),
 	}
 }
 
-func (s *service) Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*pkgdto.SearchGetResponse[dto.EmployeeResponse], error) {
-	employees, info, err := s.EmployeeRepository.FindAll(ctx, payload, &payload.Pagination)
+func (s *service) Find(ctx context.Context, payload *dto.EmployeeSearchRequest) (*pkgdto.SearchGetResponse[dto.EmployeeResponse], error) {
+	// Deleted employees are only listed to callers who may restore them.
+	if payload.DeletedFilter.Any() {
+		if err := auth.Authorize(ctx, auth.Permission(enum.EmployeesDelete)); err != nil {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+		}
+	}
+	divisionIDs, err := s.divisionFilter(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	employees, info, err := s.EmployeeRepository.FindAll(ctx, payload, &payload.Pagination, divisionIDs)
 	if err != nil {
 		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
@@ -52,6 +127,40 @@ func (s *service) Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*
 	return result, nil
 }
 
+// divisionFilter resolves the divisions a search is restricted to, nil meaning
+// every division. Division-scoped callers never get past their own division,
+// even when it sits below the requested one.
+func (s *service) divisionFilter(ctx context.Context, payload *dto.EmployeeSearchRequest) ([]uint, error) {
+	var divisionIDs []uint
+	if payload.DivisionID != nil {
+		divisionIDs = []uint{*payload.DivisionID}
+		if payload.IncludeSubdivisions {
+			descendants, err := s.DivisionRepository.FindDescendants(ctx, *payload.DivisionID)
+			if err != nil {
+				return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+			}
+			for _, division := range descendants {
+				divisionIDs = append(divisionIDs, division.ID)
+			}
+		}
+	}
+
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok || principal.ScopeDivisionID == nil {
+		return divisionIDs, nil
+	}
+	scope := *principal.ScopeDivisionID
+	if divisionIDs == nil {
+		return []uint{scope}, nil
+	}
+	for _, id := range divisionIDs {
+		if id == scope {
+			return []uint{scope}, nil
+		}
+	}
+	return []uint{}, nil
+}
+
 func (s *service) FindByID(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.EmployeeDetailResponse, error) {
 	data, err := s.EmployeeRepository.FindByID(ctx, payload.ID, true)
 	if err != nil {
@@ -60,24 +169,99 @@ func (s *service) FindByID(ctx context.Context, payload *pkgdto.ByIDRequest) (*d
 		}
 		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
+	if err := authorizeRead(ctx, &data); err != nil {
+		return &dto.EmployeeDetailResponse{}, err
+	}
 
-	result := &dto.EmployeeDetailResponse{
-		EmployeeResponse: dto.EmployeeResponse{
-			ID:       data.ID,
-			Fullname: data.Fullname,
-			Email:    data.Email,
-		},
-		Role: dto.RoleResponse{
-			ID:   data.Role.ID,
-			Name: data.Role.Name,
-		},
-		Division: dto.DivisionResponse{
-			ID:   data.Division.ID,
-			Name: data.Division.Name,
-		},
+	return newEmployeeDetailResponse(&data), nil
+}
+
+// Store creates an employee without a password and emails them a single-use
+// invitation to set one. The caller must hold every permission of the role
+// given to the employee.
+func (s *service) Store(ctx context.Context, payload *dto.CreateEmployeeRequestBody) (*dto.InvitedEmployeeResponse, error) {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, auth.ErrUnauthenticated)
+	}
+	if _, err := s.RoleRepository.FindByID(ctx, *payload.RoleID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("role not found"))
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	permissions, err := s.RoleService.ResolvePermissions(ctx, *payload.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx,
+		auth.Permission(enum.EmployeesWrite),
+		auth.NotImpersonating(),
+		auth.InDivision(*payload.DivisionID),
+		auth.Includes(permissions, nil),
+	); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
 	}
 
-	return result, nil
+	if _, err := s.DivisionRepository.FindByID(ctx, *payload.DivisionID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("division not found"))
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	isExist, err := s.EmployeeRepository.ExistByEmail(ctx, &payload.Email)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if isExist {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("employee already exists"))
+	}
+	if payload.EmployeeNumber != nil {
+		isExist, err := s.EmployeeRepository.ExistByEmployeeNumber(ctx, *payload.EmployeeNumber)
+		if err != nil {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		if isExist {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("employee number already exists"))
+		}
+	}
+	if payload.ManagerID != nil {
+		if err := s.checkManager(ctx, 0, *payload.ManagerID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	employee := model.Employee{
+		Fullname:          payload.Fullname,
+		Email:             payload.Email,
+		RoleID:            *payload.RoleID,
+		DivisionID:        *payload.DivisionID,
+		ManagerID:         payload.ManagerID,
+		Status:            string(enum.EmployeeInvited),
+		StatusEffectiveAt: &now,
+	}
+	payload.EmployeeProfileRequestBody.Apply(&employee)
+	if _, err := s.EmployeeRepository.Save(ctx, &employee); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	invitation, err := s.invite(ctx, &employee, principal.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.EmployeeRepository.FindByID(ctx, employee.ID, true)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return &dto.InvitedEmployeeResponse{
+		EmployeeDetailResponse: *newEmployeeDetailResponse(&data),
+		InvitationExpiresAt:    invitation.ExpiresAt,
+	}, nil
 }
 
 func (s *service) UpdateById(ctx context.Context, payload *dto.UpdateEmployeeRequestBody) (*dto.EmployeeDetailResponse, error) {
@@ -88,29 +272,74 @@ func (s *service) UpdateById(ctx context.Context, payload *dto.UpdateEmployeeReq
 		}
 		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
+	// Employees may edit their own profile, but moving anyone to another role,
+	// division or manager, or changing their employee number, needs
+	// employees:write. A scoped role cannot be used to move employees out of its
+	// division or to hand out roles.
+	policies := []auth.Policy{
+		auth.SelfOr(employee.ID, auth.Permission(enum.EmployeesWrite), auth.InDivision(employee.DivisionID)),
+	}
+	if payload.DivisionID != nil {
+		policies = append(policies, auth.Permission(enum.EmployeesWrite), auth.InDivision(*payload.DivisionID))
+	}
+	if payload.EmployeeNumber != nil || payload.ManagerID != nil {
+		policies = append(policies, auth.Permission(enum.EmployeesWrite))
+	}
+	roleChanged := payload.RoleID != nil && *payload.RoleID != employee.RoleID
+	if payload.RoleID != nil {
+		policies = append(policies, auth.Permission(enum.EmployeesWrite))
+	}
+	if roleChanged {
+		// as in AssignRole, the caller must hold every permission of the role
+		if _, err := s.RoleRepository.FindByID(ctx, *payload.RoleID); err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("role not found"))
+			}
+			return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		permissions, err := s.RoleService.ResolvePermissions(ctx, *payload.RoleID)
+		if err != nil {
+			return &dto.EmployeeDetailResponse{}, err
+		}
+		policies = append(policies, auth.NotImpersonating(), auth.Unscoped(), auth.Includes(permissions, employee.ScopeDivisionID))
+	}
+	if err := auth.Authorize(ctx, policies...); err != nil {
+		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	transferred := payload.DivisionID != nil && *payload.DivisionID != employee.DivisionID
+
+	if payload.EmployeeNumber != nil && *payload.EmployeeNumber != "" &&
+		(employee.EmployeeNumber == nil || *employee.EmployeeNumber != *payload.EmployeeNumber) {
+		isExist, err := s.EmployeeRepository.ExistByEmployeeNumber(ctx, *payload.EmployeeNumber)
+		if err != nil {
+			return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		if isExist {
+			return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("employee number already exists"))
+		}
+	}
+
+	if payload.ManagerID != nil && *payload.ManagerID != 0 {
+		if err := s.checkManager(ctx, employee.ID, *payload.ManagerID); err != nil {
+			return &dto.EmployeeDetailResponse{}, err
+		}
+	}
 
 	_, err = s.EmployeeRepository.Edit(ctx, &employee, payload)
 	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
 		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
-
-	result := &dto.EmployeeDetailResponse{
-		EmployeeResponse: dto.EmployeeResponse{
-			ID:       employee.ID,
-			Fullname: employee.Fullname,
-			Email:    employee.Email,
-		},
-		Role: dto.RoleResponse{
-			ID:   employee.Role.ID,
-			Name: employee.Role.Name,
-		},
-		Division: dto.DivisionResponse{
-			ID:   employee.Division.ID,
-			Name: employee.Division.Name,
-		},
+	// tokens carry the role and division, so they are reissued with the new ones
+	if roleChanged || transferred {
+		if err := util.RevocationStore.RevokeEmployee(ctx, employee.ID, time.Now()); err != nil {
+			return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
 	}
 
-	return result, nil
+	return newEmployeeDetailResponse(&employee), nil
 }
 
 func (s *service) DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.EmployeeWithCUDResponse, error) {
@@ -121,6 +350,9 @@ func (s *service) DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (
 		}
 		return &dto.EmployeeWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
+	if err := auth.Authorize(ctx, auth.Permission(enum.EmployeesDelete), auth.NotImpersonating(), auth.InDivision(employee.DivisionID)); err != nil {
+		return &dto.EmployeeWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
 	_, err = s.EmployeeRepository.Destroy(ctx, &employee)
 	if err != nil {
 		return &dto.EmployeeWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
@@ -139,3 +371,702 @@ func (s *service) DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (
 
 	return result, nil
 }
+
+// PurgeById removes the employee and everything recorded about them for good,
+// whether they were soft-deleted before or not.
+func (s *service) PurgeById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.EmployeeWithCUDResponse, error) {
+	if err := auth.Authorize(ctx, auth.Permission(enum.EmployeesDelete), auth.Permission(enum.RecordsPurge), auth.NotImpersonating(), auth.Unscoped()); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	employee, err := s.EmployeeRepository.FindByIDUnscoped(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := s.EmployeeRepository.Purge(ctx, &employee); err != nil {
+		if errors.Is(err, repository.ErrInUse) {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Conflict, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := util.RevocationStore.RevokeEmployee(ctx, employee.ID, time.Now()); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := &dto.EmployeeWithCUDResponse{
+		EmployeeResponse: dto.EmployeeResponse{
+			ID:       employee.ID,
+			Fullname: employee.Fullname,
+			Email:    employee.Email,
+		},
+		CreatedAt: employee.CreatedAt,
+		UpdatedAt: employee.UpdatedAt,
+		DeletedAt: employee.DeletedAt,
+	}
+	return result, nil
+}
+
+// Restore undeletes the employee, unless their email or employee number was
+// taken in the meantime, or their division or role is still deleted.
+func (s *service) Restore(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.EmployeeDetailResponse, error) {
+	employee, err := s.EmployeeRepository.FindDeletedByID(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := auth.Authorize(ctx, auth.Permission(enum.EmployeesDelete), auth.NotImpersonating(), auth.InDivision(employee.DivisionID)); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+
+	isExist, err := s.EmployeeRepository.ExistByEmail(ctx, &employee.Email)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if isExist {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("email already exists"))
+	}
+	if employee.EmployeeNumber != nil {
+		isExist, err := s.EmployeeRepository.ExistByEmployeeNumber(ctx, *employee.EmployeeNumber)
+		if err != nil {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		if isExist {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("employee number already exists"))
+		}
+	}
+	if _, err := s.DivisionRepository.FindByID(ctx, employee.DivisionID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Conflict, errors.New("division is deleted"))
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if _, err := s.RoleRepository.FindByID(ctx, employee.RoleID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Conflict, errors.New("role is deleted"))
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	if _, err := s.EmployeeRepository.Restore(ctx, &employee); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return s.FindByID(ctx, &pkgdto.ByIDRequest{ID: employee.ID})
+}
+
+// AssignRole sets the role of an employee, optionally limited to one division.
+// The caller must hold every permission of the role. Access tokens issued
+// before are revoked so the new permissions apply on the next refresh.
+func (s *service) AssignRole(ctx context.Context, payload *dto.AssignRoleRequestBody) (*dto.EmployeeDetailResponse, error) {
+	if err := auth.Authorize(ctx, auth.Permission(enum.EmployeesWrite), auth.NotImpersonating(), auth.Unscoped()); err != nil {
+		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+
+	employee, err := s.EmployeeRepository.FindByID(ctx, *payload.ID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if _, err := s.RoleRepository.FindByID(ctx, *payload.RoleID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("role not found"))
+		}
+		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	permissions, err := s.RoleService.ResolvePermissions(ctx, *payload.RoleID)
+	if err != nil {
+		return &dto.EmployeeDetailResponse{}, err
+	}
+	if err := auth.Authorize(ctx, auth.Includes(permissions, payload.ScopeDivisionID)); err != nil {
+		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	if payload.ScopeDivisionID != nil {
+		if _, err := s.DivisionRepository.FindByID(ctx, *payload.ScopeDivisionID); err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("division not found"))
+			}
+			return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+
+	if _, err := s.EmployeeRepository.EditRoleAssignment(ctx, &employee, *payload.RoleID, payload.ScopeDivisionID); err != nil {
+		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := util.RevocationStore.RevokeEmployee(ctx, employee.ID, time.Now()); err != nil {
+		return &dto.EmployeeDetailResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	return s.FindByID(ctx, &pkgdto.ByIDRequest{ID: employee.ID})
+}
+
+// ChangePassword replaces the employee's password after verifying the current one.
+// Refresh tokens issued before the change are revoked so other devices have to log in again.
+func (s *service) ChangePassword(ctx context.Context, employeeID uint, payload *dto.ChangePasswordRequestBody) error {
+	employee, err := s.EmployeeRepository.FindByID(ctx, employeeID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	if !pkgutil.CompareHashPassword(payload.CurrentPassword, employee.Password) {
+		return res.ErrorBuilder(
+			&res.ErrorConstant.PasswordIncorrect,
+			errors.New(res.ErrorConstant.PasswordIncorrect.Response.Meta.Message),
+		)
+	}
+	if violations := s.PasswordPolicy.Validate(payload.NewPassword, employee.Email, employee.Fullname); violations != nil {
+		return res.ErrorWithDetailsBuilder(&res.ErrorConstant.Validation, violations, violations)
+	}
+
+	hashedPassword, err := pkgutil.HashPassword(payload.NewPassword)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if _, err := s.EmployeeRepository.EditPassword(ctx, &employee, hashedPassword); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := s.RefreshTokenRepository.RevokeByEmployeeID(ctx, employee.ID); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	return nil
+}
+
+func newEmployeeDetailResponse(data *model.Employee) *dto.EmployeeDetailResponse {
+	return &dto.EmployeeDetailResponse{
+		EmployeeResponse: dto.EmployeeResponse{
+			ID:       data.ID,
+			Fullname: data.Fullname,
+			Email:    data.Email,
+		},
+		EmployeeProfileResponse: dto.EmployeeProfileResponse{
+			JobTitle:       data.JobTitle,
+			Phone:          data.Phone,
+			EmployeeNumber: data.EmployeeNumber,
+			Office:         data.Office,
+			Building:       data.Building,
+			Floor:          data.Floor,
+			TimeZone:       data.TimeZone,
+			Locale:         data.Locale,
+		},
+		Role: dto.RoleResponse{
+			ID:   data.Role.ID,
+			Name: data.Role.Name,
+		},
+		Division: dto.DivisionResponse{
+			ID:   data.Division.ID,
+			Name: data.Division.Name,
+		},
+		ScopeDivisionID: data.ScopeDivisionID,
+		ManagerID:       data.ManagerID,
+		EmployeeStatusResponse: dto.EmployeeStatusResponse{
+			Status:            data.Status,
+			StatusEffectiveAt: data.StatusEffectiveAt,
+		},
+	}
+}
+
+// FindManagerChain lists the managers above the employee, the direct manager
+// first. Depth is 1 for the direct manager. Division-scoped callers only see
+// the managers of their division.
+func (s *service) FindManagerChain(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.EmployeeReportResponse, error) {
+	if err := s.checkReadable(ctx, payload.ID); err != nil {
+		return nil, err
+	}
+	managers, err := s.EmployeeRepository.FindManagerChain(ctx, payload.ID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := make([]dto.EmployeeReportResponse, 0, len(managers))
+	for i, manager := range managers {
+		if authorizeRead(ctx, &manager) != nil {
+			continue
+		}
+		result = append(result, newEmployeeReportResponse(&manager, i+1))
+	}
+	return result, nil
+}
+
+// FindDirectReports lists the employees reporting to the employee. Division-
+// scoped callers only see the reports of their division.
+func (s *service) FindDirectReports(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.EmployeeReportResponse, error) {
+	if err := s.checkReadable(ctx, payload.ID); err != nil {
+		return nil, err
+	}
+	reports, err := s.EmployeeRepository.FindDirectReports(ctx, payload.ID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := make([]dto.EmployeeReportResponse, 0, len(reports))
+	for _, report := range reports {
+		if authorizeRead(ctx, &report) != nil {
+			continue
+		}
+		result = append(result, newEmployeeReportResponse(&report, 1))
+	}
+	return result, nil
+}
+
+// FindReports lists everyone below the employee in the reporting structure, up
+// to payload.Depth levels down. Division-scoped callers only see the reports
+// of their division.
+func (s *service) FindReports(ctx context.Context, payload *dto.EmployeeReportsRequest) ([]dto.EmployeeReportResponse, error) {
+	if err := s.checkReadable(ctx, payload.ID); err != nil {
+		return nil, err
+	}
+	reports, err := s.EmployeeRepository.FindReports(ctx, payload.ID, payload.Depth)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := make([]dto.EmployeeReportResponse, 0, len(reports))
+	for _, report := range reports {
+		if authorizeRead(ctx, &report.Employee) != nil {
+			continue
+		}
+		result = append(result, newEmployeeReportResponse(&report.Employee, report.Depth))
+	}
+	return result, nil
+}
+
+// ChangeStatus moves the employee to another stage of employment, following
+// enum.EmployeeStatus transitions. Employees who can no longer sign in are
+// signed out everywhere.
+func (s *service) ChangeStatus(ctx context.Context, payload *dto.ChangeEmployeeStatusRequestBody) (*dto.EmployeeDetailResponse, error) {
+	employee, err := s.EmployeeRepository.FindByID(ctx, payload.ID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := auth.Authorize(ctx, auth.Permission(enum.EmployeesWrite), auth.NotImpersonating(), auth.InDivision(employee.DivisionID)); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	principal, _ := auth.PrincipalFrom(ctx)
+	if principal.EmployeeID == employee.ID {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("employees cannot change their own status"))
+	}
+
+	from, to := enum.EmployeeStatus(employee.Status), enum.EmployeeStatus(payload.Status)
+	if !from.CanTransitionTo(to) {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.BadRequest, fmt.Errorf("status cannot change from %s to %s", from, to))
+	}
+	effectiveAt := time.Now()
+	if payload.EffectiveAt != nil {
+		if payload.EffectiveAt.After(effectiveAt) {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("effective_at cannot be in the future"))
+		}
+		effectiveAt = *payload.EffectiveAt
+	}
+
+	change := &model.EmployeeStatusChange{
+		EmployeeID:  employee.ID,
+		FromStatus:  string(from),
+		ToStatus:    string(to),
+		EffectiveAt: effectiveAt,
+		Reason:      payload.Reason,
+		ChangedByID: &principal.EmployeeID,
+	}
+	if _, err := s.EmployeeRepository.EditStatus(ctx, &employee, change); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !to.CanSignIn() {
+		if err := util.RevocationStore.RevokeEmployee(ctx, employee.ID, time.Now()); err != nil {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		if err := s.RefreshTokenRepository.RevokeByEmployeeID(ctx, employee.ID); err != nil {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+
+	return s.FindByID(ctx, &pkgdto.ByIDRequest{ID: employee.ID})
+}
+
+// FindStatusChanges lists the status history of the employee, the latest first.
+func (s *service) FindStatusChanges(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.EmployeeStatusChangeResponse, error) {
+	if err := s.checkReadable(ctx, payload.ID); err != nil {
+		return nil, err
+	}
+	changes, err := s.EmployeeRepository.FindStatusChanges(ctx, payload.ID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := make([]dto.EmployeeStatusChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, dto.EmployeeStatusChangeResponse{
+			ID:          change.ID,
+			EmployeeID:  change.EmployeeID,
+			FromStatus:  change.FromStatus,
+			ToStatus:    change.ToStatus,
+			EffectiveAt: change.EffectiveAt,
+			Reason:      change.Reason,
+			ChangedByID: change.ChangedByID,
+			CreatedAt:   change.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// SchedulePendingChange queues a division, role or status change of the
+// employee for payload.EffectiveAt. Whether the status transition is allowed
+// is checked once the change is due, as other changes may be applied first.
+func (s *service) SchedulePendingChange(ctx context.Context, payload *dto.SchedulePendingChangeRequestBody) (*dto.PendingChangeResponse, error) {
+	employee, err := s.EmployeeRepository.FindByID(ctx, payload.ID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	// The same rules as for immediate changes apply: scoped roles cannot move
+	// employees out of their division or hand out roles, and nobody can hand
+	// out a role with permissions they lack. The scheduler applies the change
+	// without a caller, so this is the only check.
+	policies := []auth.Policy{auth.Permission(enum.EmployeesWrite), auth.NotImpersonating(), auth.InDivision(employee.DivisionID)}
+	if payload.DivisionID != nil {
+		policies = append(policies, auth.InDivision(*payload.DivisionID))
+	}
+	if payload.RoleID != nil {
+		if _, err := s.RoleRepository.FindByID(ctx, *payload.RoleID); err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("role not found"))
+			}
+			return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+		permissions, err := s.RoleService.ResolvePermissions(ctx, *payload.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, auth.Unscoped(), auth.Includes(permissions, employee.ScopeDivisionID))
+	}
+	if err := auth.Authorize(ctx, policies...); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	principal, _ := auth.PrincipalFrom(ctx)
+
+	if payload.DivisionID == nil && payload.RoleID == nil && payload.Status == nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("division_id, role_id or status is required"))
+	}
+	if payload.Status != nil && principal.EmployeeID == employee.ID {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("employees cannot change their own status"))
+	}
+	if !payload.EffectiveAt.After(time.Now()) {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("effective_at must be in the future"))
+	}
+	if payload.DivisionID != nil {
+		if _, err := s.DivisionRepository.FindByID(ctx, *payload.DivisionID); err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("division not found"))
+			}
+			return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+
+	change := &model.EmployeePendingChange{
+		EmployeeID:    employee.ID,
+		DivisionID:    payload.DivisionID,
+		RoleID:        payload.RoleID,
+		Status:        payload.Status,
+		EffectiveAt:   payload.EffectiveAt,
+		State:         string(enum.PendingChangePending),
+		Reason:        payload.Reason,
+		ScheduledByID: &principal.EmployeeID,
+	}
+	if _, err := s.PendingChangeRepository.Save(ctx, change); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return newPendingChangeResponse(change), nil
+}
+
+// FindPendingChanges lists the changes scheduled for the employee, the next one
+// first.
+func (s *service) FindPendingChanges(ctx context.Context, payload *dto.PendingChangeSearchRequest) ([]dto.PendingChangeResponse, error) {
+	if err := s.checkReadable(ctx, payload.ID); err != nil {
+		return nil, err
+	}
+	state := payload.State
+	if state == "" {
+		state = string(enum.PendingChangePending)
+	}
+	changes, err := s.PendingChangeRepository.FindByEmployeeID(ctx, payload.ID, state)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := make([]dto.PendingChangeResponse, 0, len(changes))
+	for i := range changes {
+		result = append(result, *newPendingChangeResponse(&changes[i]))
+	}
+	return result, nil
+}
+
+func (s *service) CancelPendingChange(ctx context.Context, payload *dto.PendingChangeRequest) (*dto.PendingChangeResponse, error) {
+	change, err := s.PendingChangeRepository.FindByID(ctx, payload.ChangeID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if change.EmployeeID != payload.ID {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, constant.RECORD_NOT_FOUND)
+	}
+	employee, err := s.EmployeeRepository.FindByID(ctx, change.EmployeeID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := auth.Authorize(ctx, auth.Permission(enum.EmployeesWrite), auth.NotImpersonating(), auth.InDivision(employee.DivisionID)); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	principal, _ := auth.PrincipalFrom(ctx)
+
+	ok, err := s.PendingChangeRepository.Cancel(ctx, change, principal.EmployeeID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !ok {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("change is no longer pending"))
+	}
+	return newPendingChangeResponse(change), nil
+}
+
+// applyDueChanges applies the changes that are due by now, at most
+// pendingChangeBatchSize of them, and returns how many were applied. Changes
+// that can no longer be made are marked failed, and so are changes that fail
+// with an error, so that they cannot hold up the changes due after them.
+func (s *service) applyDueChanges(ctx context.Context, now time.Time) (int, error) {
+	changes, err := s.PendingChangeRepository.FindDue(ctx, now, pendingChangeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for i := range changes {
+		ok, err := s.applyPendingChange(ctx, &changes[i])
+		if ok {
+			applied++
+		}
+		if err != nil {
+			logrus.Errorf("apply pending change %d: %v", changes[i].ID, err)
+			if _, err := s.failPendingChange(ctx, &changes[i], failureReason(err)); err != nil {
+				logrus.Errorf("mark pending change %d failed: %v", changes[i].ID, err)
+			}
+		}
+	}
+	return applied, nil
+}
+
+// failureReason fits err in the error column of a pending change.
+func failureReason(err error) string {
+	reason := err.Error()
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+	return reason
+}
+
+func (s *service) applyPendingChange(ctx context.Context, change *model.EmployeePendingChange) (bool, error) {
+	employee, err := s.EmployeeRepository.FindByID(ctx, change.EmployeeID, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return s.failPendingChange(ctx, change, "employee not found")
+		}
+		return false, err
+	}
+	if change.DivisionID != nil {
+		if _, err := s.DivisionRepository.FindByID(ctx, *change.DivisionID); err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				return s.failPendingChange(ctx, change, "division not found")
+			}
+			return false, err
+		}
+	}
+	if change.RoleID != nil {
+		if _, err := s.RoleRepository.FindByID(ctx, *change.RoleID); err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				return s.failPendingChange(ctx, change, "role not found")
+			}
+			return false, err
+		}
+	}
+	from := enum.EmployeeStatus(employee.Status)
+	if change.Status != nil && *change.Status != employee.Status {
+		if to := enum.EmployeeStatus(*change.Status); !from.CanTransitionTo(to) {
+			return s.failPendingChange(ctx, change, fmt.Sprintf("status cannot change from %s to %s", from, to))
+		}
+	}
+
+	roleID := employee.RoleID
+	ok, err := s.PendingChangeRepository.Apply(ctx, change, &employee)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	// Access tokens carry the role and status of the employee, so they are
+	// revoked when either changes.
+	signOut := change.Status != nil && !enum.EmployeeStatus(*change.Status).CanSignIn()
+	if signOut || (change.RoleID != nil && *change.RoleID != roleID) {
+		if err := util.RevocationStore.RevokeEmployee(ctx, employee.ID, time.Now()); err != nil {
+			return true, err
+		}
+	}
+	if signOut {
+		if err := s.RefreshTokenRepository.RevokeByEmployeeID(ctx, employee.ID); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+func (s *service) failPendingChange(ctx context.Context, change *model.EmployeePendingChange, reason string) (bool, error) {
+	_, err := s.PendingChangeRepository.MarkFailed(ctx, change, reason)
+	return false, err
+}
+
+// invite emails the employee a link to set their first password, expiring any
+// invitation sent before.
+func (s *service) invite(ctx context.Context, employee *model.Employee, invitedByID uint) (*model.EmployeeInvitation, error) {
+	if err := s.InvitationRepository.InvalidateByEmployeeID(ctx, employee.ID); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	token, err := pkgutil.GenerateRandomToken(32)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	invitation, err := s.InvitationRepository.Save(ctx, &model.EmployeeInvitation{
+		EmployeeID:  employee.ID,
+		InvitedByID: &invitedByID,
+		TokenHash:   pkgutil.HashToken(token),
+		ExpiresAt:   time.Now().Add(INVITATION_EXP),
+	})
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	message := notifier.Message{
+		To:      employee.Email,
+		Subject: "You have been invited",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nAn account has been created for you. Use the link below to set your password. The link expires in %s.\n\n%s?token=%s",
+			employee.Fullname,
+			INVITATION_EXP,
+			INVITATION_URL,
+			token,
+		),
+	}
+	if err := s.Notifier.Send(ctx, message); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return invitation, nil
+}
+
+func (s *service) checkExist(ctx context.Context, id uint) error {
+	isExist, err := s.EmployeeRepository.ExistByID(ctx, id)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !isExist {
+		return res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("employee not found"))
+	}
+	return nil
+}
+
+// checkReadable is checkExist for the details of one employee, which
+// division-scoped callers only see within their division.
+func (s *service) checkReadable(ctx context.Context, id uint) error {
+	employee, err := s.EmployeeRepository.FindByID(ctx, id, false)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.NotFound, errors.New("employee not found"))
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return authorizeRead(ctx, &employee)
+}
+
+// authorizeRead keeps division-scoped callers to the employees of their
+// division and themselves, as Find does for lists.
+func authorizeRead(ctx context.Context, employee *model.Employee) error {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok || principal.ScopeDivisionID == nil {
+		return nil
+	}
+	if err := auth.Authorize(ctx, auth.SelfOr(employee.ID, auth.InDivision(employee.DivisionID))); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	return nil
+}
+
+// checkManager rejects a manager that does not exist, or that already reports
+// to the employee, which would close a loop in the reporting structure.
+func (s *service) checkManager(ctx context.Context, employeeID, managerID uint) error {
+	if managerID == employeeID {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("employee cannot be their own manager"))
+	}
+	isExist, err := s.EmployeeRepository.ExistByID(ctx, managerID)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if !isExist {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("manager not found"))
+	}
+
+	chain, err := s.EmployeeRepository.FindManagerChain(ctx, managerID)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	for _, manager := range chain {
+		if manager.ID == employeeID {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, fmt.Errorf("employee %d already reports to employee %d", managerID, employeeID))
+		}
+	}
+	return nil
+}
+
+func newEmployeeReportResponse(data *model.Employee, depth int) dto.EmployeeReportResponse {
+	return dto.EmployeeReportResponse{
+		EmployeeResponse: dto.EmployeeResponse{
+			ID:       data.ID,
+			Fullname: data.Fullname,
+			Email:    data.Email,
+		},
+		ManagerID: data.ManagerID,
+		Depth:     depth,
+	}
+}
+
+func newPendingChangeResponse(data *model.EmployeePendingChange) *dto.PendingChangeResponse {
+	return &dto.PendingChangeResponse{
+		ID:            data.ID,
+		EmployeeID:    data.EmployeeID,
+		DivisionID:    data.DivisionID,
+		RoleID:        data.RoleID,
+		Status:        data.Status,
+		EffectiveAt:   data.EffectiveAt,
+		State:         data.State,
+		Reason:        data.Reason,
+		ScheduledByID: data.ScheduledByID,
+		CanceledByID:  data.CanceledByID,
+		AppliedAt:     data.AppliedAt,
+		Error:         data.Error,
+		CreatedAt:     data.CreatedAt,
+	}
+}
@@ -3,11 +3,15 @@ package employee
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/mocks"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +21,7 @@ var (
 	testID                    = uint(1)
 	testFullname              = "Vincent Luis Hubbard"
 	ctx                       = context.Background()
+	adminCtx                  = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
 	testEmployeeService       = NewService(factory.NewFactory())
 	testUpdateEmployeePayload = dto.UpdateEmployeeRequestBody{
 		ID:         &testID,
@@ -25,7 +30,7 @@ var (
 		DivisionID: &testDivisionID,
 		RoleID:     &testAdminRoleID,
 	}
-	testFindAllPayload  = pkgdto.SearchGetRequest{}
+	testFindAllPayload  = dto.EmployeeSearchRequest{}
 	testFindByIdPayload = pkgdto.ByIDRequest{ID: 1}
 )
 
@@ -77,7 +82,7 @@ func TestEmployeeServiceUpdateByIdSuccess(t *testing.T) {
 	seeder.NewSeeder().SeedAll()
 
 	asserts := assert.New(t)
-	res, err := testEmployeeService.UpdateById(ctx, &testUpdateEmployeePayload)
+	res, err := testEmployeeService.UpdateById(adminCtx, &testUpdateEmployeePayload)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -92,19 +97,138 @@ func TestEmployeeServiceUpdateByIdRecordNotFound(t *testing.T) {
 	seeder.NewSeeder().DeleteAll()
 
 	asserts := assert.New(t)
-	_, err := testEmployeeService.UpdateById(ctx, &testUpdateEmployeePayload)
+	_, err := testEmployeeService.UpdateById(adminCtx, &testUpdateEmployeePayload)
 	if err != nil {
 		asserts.Equal(err.Error(), "error code 404")
 	}
 }
 
+func TestEmployeeServiceUpdateByIdUnauthenticated(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	_, err := testEmployeeService.UpdateById(ctx, &testUpdateEmployeePayload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestEmployeeServiceUpdateByIdProfile(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		id       = uint(2)
+		jobTitle = "Senior Accountant"
+		phone    = "+6281234567890"
+		building = "Tower A"
+		timeZone = "Asia/Jakarta"
+		claims   = userClaims
+	)
+	ctx := auth.NewContext(ctx, auth.FromClaims(&claims))
+	res, err := testEmployeeService.UpdateById(ctx, &dto.UpdateEmployeeRequestBody{
+		ID:       &id,
+		JobTitle: &jobTitle,
+		Phone:    &phone,
+		Building: &building,
+		TimeZone: &timeZone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(jobTitle, res.JobTitle)
+	asserts.Equal(phone, res.Phone)
+	asserts.Equal(building, res.Building)
+	asserts.Equal(timeZone, res.TimeZone)
+	asserts.Equal("en", res.Locale)
+}
+
+func TestEmployeeServiceUpdateByIdEmployeeNumberAlreadyExist(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts        = assert.New(t)
+		id             = uint(2)
+		employeeNumber = "EMP003"
+	)
+	_, err := testEmployeeService.UpdateById(adminCtx, &dto.UpdateEmployeeRequestBody{ID: &id, EmployeeNumber: &employeeNumber})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+}
+
+func TestEmployeeServiceUpdateByIdOwnEmployeeNumberUnauthorized(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts        = assert.New(t)
+		id             = uint(2)
+		employeeNumber = "EMP999"
+		claims         = userClaims
+	)
+	ctx := auth.NewContext(ctx, auth.FromClaims(&claims))
+	_, err := testEmployeeService.UpdateById(ctx, &dto.UpdateEmployeeRequestBody{ID: &id, EmployeeNumber: &employeeNumber})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestEmployeeServiceUpdateByIdOwnRoleEscalation(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		id      = uint(2)
+		roleID  = uint(enum.Admin)
+		claims  = dto.JWTClaims{UserID: 2, RoleID: uint(enum.User), Permissions: []string{string(enum.EmployeesRead), string(enum.EmployeesWrite)}}
+	)
+	ctx := auth.NewContext(ctx, auth.FromClaims(&claims))
+	_, err := testEmployeeService.UpdateById(ctx, &dto.UpdateEmployeeRequestBody{ID: &id, RoleID: &roleID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestEmployeeServiceFindByIdOutOfScope(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		divisionID = uint(enum.Finance)
+		claims     = dto.JWTClaims{UserID: 1, Permissions: enum.PermissionNames(), ScopeDivisionID: &divisionID}
+		scopedCtx  = auth.NewContext(ctx, auth.FromClaims(&claims))
+	)
+	_, err := testEmployeeService.FindByID(scopedCtx, &pkgdto.ByIDRequest{ID: 3})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+
+	res, err := testEmployeeService.FindByID(scopedCtx, &pkgdto.ByIDRequest{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(uint(2), res.ID)
+}
+
 func TestEmployeeServiceDeleteByIdSuccess(t *testing.T) {
 	database.GetConnection()
 	seeder.NewSeeder().DeleteAll()
 	seeder.NewSeeder().SeedAll()
 
 	asserts := assert.New(t)
-	res, err := testEmployeeService.DeleteById(ctx, &testFindByIdPayload)
+	res, err := testEmployeeService.DeleteById(adminCtx, &testFindByIdPayload)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -116,8 +240,621 @@ func TestEmployeeServiceDeleteByIdRecordNotFound(t *testing.T) {
 	seeder.NewSeeder().DeleteAll()
 
 	asserts := assert.New(t)
-	_, err := testEmployeeService.DeleteById(ctx, &testFindByIdPayload)
+	_, err := testEmployeeService.DeleteById(adminCtx, &testFindByIdPayload)
 	if asserts.Error(err) {
 		asserts.Equal(err.Error(), "error code 404")
 	}
 }
+
+func TestEmployeeServiceFindAllDivisionScoped(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		divisionID = uint(enum.Finance)
+		claims     = dto.JWTClaims{UserID: 1, Permissions: enum.PermissionNames(), ScopeDivisionID: &divisionID}
+	)
+	res, err := testEmployeeService.Find(auth.NewContext(ctx, auth.FromClaims(&claims)), &testFindAllPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(res.Data, 2)
+}
+
+func TestEmployeeServiceUpdateByIdOutOfScope(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		id         = uint(3)
+		divisionID = uint(enum.Finance)
+		claims     = dto.JWTClaims{UserID: 1, Permissions: enum.PermissionNames(), ScopeDivisionID: &divisionID}
+	)
+	_, err := testEmployeeService.UpdateById(auth.NewContext(ctx, auth.FromClaims(&claims)), &dto.UpdateEmployeeRequestBody{ID: &id, Fullname: &testFullname})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestEmployeeServiceDeleteByIdOutOfScope(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		divisionID = uint(enum.Finance)
+		claims     = dto.JWTClaims{UserID: 1, Permissions: enum.PermissionNames(), ScopeDivisionID: &divisionID}
+	)
+	_, err := testEmployeeService.DeleteById(auth.NewContext(ctx, auth.FromClaims(&claims)), &pkgdto.ByIDRequest{ID: 3})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestEmployeeServiceAssignRoleSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		id         = uint(3)
+		roleID     = uint(enum.Admin)
+		divisionID = uint(2)
+		payload    = dto.AssignRoleRequestBody{ID: &id, RoleID: &roleID, ScopeDivisionID: &divisionID}
+	)
+	res, err := testEmployeeService.AssignRole(adminCtx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(roleID, res.Role.ID)
+	if asserts.NotNil(res.ScopeDivisionID) {
+		asserts.Equal(divisionID, *res.ScopeDivisionID)
+	}
+}
+
+func TestEmployeeServiceAssignRoleByScopedAdmin(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		id         = uint(2)
+		roleID     = uint(enum.Admin)
+		divisionID = uint(enum.Finance)
+		claims     = dto.JWTClaims{UserID: 1, Permissions: enum.PermissionNames(), ScopeDivisionID: &divisionID}
+	)
+	_, err := testEmployeeService.AssignRole(auth.NewContext(ctx, auth.FromClaims(&claims)), &dto.AssignRoleRequestBody{ID: &id, RoleID: &roleID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestEmployeeServiceAssignRoleEscalation(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		id      = uint(2)
+		roleID  = uint(enum.Admin)
+		claims  = dto.JWTClaims{UserID: 2, RoleID: uint(enum.User), Permissions: []string{string(enum.EmployeesRead), string(enum.EmployeesWrite)}}
+	)
+	_, err := testEmployeeService.AssignRole(auth.NewContext(ctx, auth.FromClaims(&claims)), &dto.AssignRoleRequestBody{ID: &id, RoleID: &roleID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestEmployeeServiceChangePasswordSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	payload := dto.ChangePasswordRequestBody{CurrentPassword: "123abcABC!", NewPassword: "456defDEF!"}
+	asserts.NoError(testEmployeeService.ChangePassword(ctx, testID, &payload))
+}
+
+func TestEmployeeServiceChangePasswordIncorrectCurrentPassword(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	payload := dto.ChangePasswordRequestBody{CurrentPassword: "1234567890", NewPassword: "456defDEF!"}
+	err := testEmployeeService.ChangePassword(ctx, testID, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestEmployeeServiceChangePasswordPolicyViolation(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	payload := dto.ChangePasswordRequestBody{CurrentPassword: "123abcABC!", NewPassword: "a"}
+	err := testEmployeeService.ChangePassword(ctx, testID, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestEmployeeServiceFindManagerChainSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	res, err := testEmployeeService.FindManagerChain(adminCtx, &pkgdto.ByIDRequest{ID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(res, 2) {
+		asserts.Equal(uint(2), res[0].ID)
+		asserts.Equal(1, res[0].Depth)
+		asserts.Equal(uint(1), res[1].ID)
+		asserts.Equal(2, res[1].Depth)
+	}
+}
+
+func TestEmployeeServiceFindReportsSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	res, err := testEmployeeService.FindReports(adminCtx, &dto.EmployeeReportsRequest{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(res, 2)
+
+	res, err = testEmployeeService.FindReports(adminCtx, &dto.EmployeeReportsRequest{ID: 1, Depth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(res, 1) {
+		asserts.Equal(uint(2), res[0].ID)
+	}
+
+	direct, err := testEmployeeService.FindDirectReports(adminCtx, &pkgdto.ByIDRequest{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(direct, 1) {
+		asserts.Equal(uint(3), direct[0].ID)
+	}
+}
+
+func TestEmployeeServiceFindReportsOutOfScope(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		divisionID = uint(enum.Finance)
+		claims     = dto.JWTClaims{UserID: 1, Permissions: enum.PermissionNames(), ScopeDivisionID: &divisionID}
+		scopedCtx  = auth.NewContext(ctx, auth.FromClaims(&claims))
+	)
+	// bettina works in IT, below devon of Finance
+	_, err := testEmployeeService.FindManagerChain(scopedCtx, &pkgdto.ByIDRequest{ID: 3})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+
+	res, err := testEmployeeService.FindReports(scopedCtx, &dto.EmployeeReportsRequest{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(res, 1) {
+		asserts.Equal(uint(2), res[0].ID)
+	}
+
+	direct, err := testEmployeeService.FindDirectReports(scopedCtx, &pkgdto.ByIDRequest{ID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Empty(direct)
+}
+
+func TestEmployeeServiceUpdateByIdManagerCycle(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts   = assert.New(t)
+		id        = uint(1)
+		managerID = uint(3)
+	)
+	// employee 3 reports to employee 2, who reports to employee 1
+	_, err := testEmployeeService.UpdateById(adminCtx, &dto.UpdateEmployeeRequestBody{ID: &id, ManagerID: &managerID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+	_, err = testEmployeeService.UpdateById(adminCtx, &dto.UpdateEmployeeRequestBody{ID: &id, ManagerID: &id})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestEmployeeServiceFindAllIncludeSubdivisions(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		divisionID = uint(enum.Finance)
+		accounting = model.Division{Name: "Accounting", ParentID: &divisionID}
+	)
+	if err := db.Create(&accounting).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&model.Employee{}).Where("id = ?", 2).Update("division_id", accounting.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := testEmployeeService.Find(ctx, &dto.EmployeeSearchRequest{DivisionID: &divisionID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(res.Data, 1)
+
+	res, err = testEmployeeService.Find(ctx, &dto.EmployeeSearchRequest{DivisionID: &divisionID, IncludeSubdivisions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(res.Data, 2)
+}
+
+func TestEmployeeServiceDeleteByIdClearsDivisionHead(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	if err := db.Model(&model.Division{}).Where("id = ?", testDivisionID).Update("head_id", 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testEmployeeService.DeleteById(adminCtx, &pkgdto.ByIDRequest{ID: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	var division model.Division
+	if err := db.First(&division, testDivisionID).Error; err != nil {
+		t.Fatal(err)
+	}
+	asserts.Nil(division.HeadID)
+}
+
+func TestEmployeeServiceStoreSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts      = assert.New(t)
+		f            = factory.NewFactory()
+		notifierMock = &mocks.NotifierMock{}
+		roleID       = uint(enum.User)
+		divisionID   = uint(enum.IT)
+		managerID    = uint(2)
+		payload      = dto.CreateEmployeeRequestBody{
+			Fullname:   "Azka Fadhli Ramadhan",
+			Email:      "azkaframadhan@superrito.com",
+			RoleID:     &roleID,
+			DivisionID: &divisionID,
+			ManagerID:  &managerID,
+		}
+	)
+	f.Notifier = notifierMock
+	payload.FillDefaults()
+
+	res, err := NewService(f).Store(adminCtx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.NotEmpty(res.ID)
+	asserts.Equal(roleID, res.Role.ID)
+	asserts.Equal("UTC", res.TimeZone)
+	asserts.True(res.InvitationExpiresAt.After(time.Now()))
+
+	message := notifierMock.Last()
+	asserts.Equal(payload.Email, message.To)
+	asserts.Contains(message.Body, INVITATION_URL+"?token=")
+}
+
+func TestEmployeeServiceStoreMorePrivilegedRole(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		roleID     = uint(enum.Admin)
+		divisionID = uint(enum.Finance)
+		claims     = dto.JWTClaims{UserID: 2, DivisionID: divisionID, Permissions: []string{string(enum.EmployeesWrite)}}
+		payload    = dto.CreateEmployeeRequestBody{
+			Fullname:   "Azka Fadhli Ramadhan",
+			Email:      "azkaframadhan@superrito.com",
+			RoleID:     &roleID,
+			DivisionID: &divisionID,
+		}
+	)
+	_, err := testEmployeeService.Store(auth.NewContext(ctx, auth.FromClaims(&claims)), &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestEmployeeServiceStoreEmailAlreadyExist(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		roleID     = uint(enum.User)
+		divisionID = uint(enum.Finance)
+		payload    = dto.CreateEmployeeRequestBody{
+			Fullname:   "Devon C Thomas",
+			Email:      "devoncthomas@superrito.com",
+			RoleID:     &roleID,
+			DivisionID: &divisionID,
+		}
+	)
+	_, err := testEmployeeService.Store(adminCtx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+}
+
+func TestEmployeeServiceChangeStatusSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		id      = uint(2)
+		payload = dto.ChangeEmployeeStatusRequestBody{ID: id, Status: string(enum.EmployeeSuspended), Reason: "policy violation"}
+	)
+
+	res, err := testEmployeeService.ChangeStatus(adminCtx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(payload.Status, res.Status)
+	asserts.NotNil(res.StatusEffectiveAt)
+
+	history, err := testEmployeeService.FindStatusChanges(adminCtx, &pkgdto.ByIDRequest{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(history, 1) {
+		asserts.Equal(string(enum.EmployeeActive), history[0].FromStatus)
+		asserts.Equal(payload.Status, history[0].ToStatus)
+		asserts.Equal(payload.Reason, history[0].Reason)
+	}
+
+	list, err := testEmployeeService.Find(ctx, &dto.EmployeeSearchRequest{Status: payload.Status})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(list.Data, 1)
+}
+
+func TestEmployeeServiceChangeStatusInvalidTransition(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	_, err := testEmployeeService.ChangeStatus(adminCtx, &dto.ChangeEmployeeStatusRequestBody{ID: 2, Status: string(enum.EmployeeTerminated)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = testEmployeeService.ChangeStatus(adminCtx, &dto.ChangeEmployeeStatusRequestBody{ID: 2, Status: string(enum.EmployeeActive)})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestEmployeeServiceSchedulePendingChangeInPast(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		divisionID = uint(enum.IT)
+	)
+	_, err := testEmployeeService.SchedulePendingChange(adminCtx, &dto.SchedulePendingChangeRequestBody{
+		ID:          2,
+		DivisionID:  &divisionID,
+		EffectiveAt: time.Now().Add(-time.Hour),
+	})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestEmployeeServiceSchedulePendingChangeRoleEscalation(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		roleID  = uint(enum.Admin)
+		claims  = dto.JWTClaims{UserID: 2, RoleID: uint(enum.User), Permissions: []string{string(enum.EmployeesRead), string(enum.EmployeesWrite)}}
+	)
+	_, err := testEmployeeService.SchedulePendingChange(auth.NewContext(ctx, auth.FromClaims(&claims)), &dto.SchedulePendingChangeRequestBody{
+		ID:          2,
+		RoleID:      &roleID,
+		EffectiveAt: time.Now().Add(time.Hour),
+	})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestEmployeeServiceCancelPendingChangeSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		roleID  = uint(enum.Admin)
+	)
+	change, err := testEmployeeService.SchedulePendingChange(adminCtx, &dto.SchedulePendingChangeRequestBody{
+		ID:          2,
+		RoleID:      &roleID,
+		EffectiveAt: time.Now().Add(24 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := dto.PendingChangeRequest{ID: 2, ChangeID: change.ID}
+	res, err := testEmployeeService.CancelPendingChange(adminCtx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(string(enum.PendingChangeCanceled), res.State)
+
+	_, err = testEmployeeService.CancelPendingChange(adminCtx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestEmployeeServiceRestoreSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		payload = pkgdto.ByIDRequest{ID: 3}
+	)
+	if _, err := testEmployeeService.DeleteById(adminCtx, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := testEmployeeService.Find(adminCtx, &dto.EmployeeSearchRequest{DeletedFilter: pkgdto.DeletedFilter{OnlyDeleted: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(deleted.Data, 1) {
+		asserts.Equal(payload.ID, deleted.Data[0].ID)
+	}
+
+	res, err := testEmployeeService.Restore(adminCtx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(payload.ID, res.ID)
+	asserts.Equal(uint(enum.IT), res.Division.ID)
+}
+
+func TestEmployeeServiceRestoreDivisionDeleted(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	if _, err := testEmployeeService.DeleteById(adminCtx, &pkgdto.ByIDRequest{ID: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&model.Division{}, uint(enum.IT)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := testEmployeeService.Restore(adminCtx, &pkgdto.ByIDRequest{ID: 3})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+}
+
+func TestEmployeeServicePurgeByIdSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		payload = pkgdto.ByIDRequest{ID: 2}
+	)
+	res, err := testEmployeeService.PurgeById(adminCtx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(payload.ID, res.ID)
+
+	// bettina reported to devon
+	bettina, err := testEmployeeService.FindByID(ctx, &pkgdto.ByIDRequest{ID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Nil(bettina.ManagerID)
+
+	_, err = testEmployeeService.Restore(adminCtx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 404")
+	}
+}
+
+func TestEmployeeServicePurgeByIdActor(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts     = assert.New(t)
+		claims      = dto.JWTClaims{UserID: 2, Permissions: enum.PermissionNames()}
+		devonCtx    = auth.NewContext(ctx, auth.FromClaims(&claims))
+		status      = string(enum.EmployeeSuspended)
+		effectiveAt = time.Now().Add(time.Hour)
+	)
+	// devon puts bettina on leave and schedules her suspension
+	if _, err := testEmployeeService.ChangeStatus(devonCtx, &dto.ChangeEmployeeStatusRequestBody{ID: 3, Status: string(enum.EmployeeOnLeave)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testEmployeeService.SchedulePendingChange(devonCtx, &dto.SchedulePendingChangeRequestBody{ID: 3, Status: &status, EffectiveAt: effectiveAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := testEmployeeService.PurgeById(adminCtx, &pkgdto.ByIDRequest{ID: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := testEmployeeService.FindStatusChanges(adminCtx, &pkgdto.ByIDRequest{ID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(history, 1) {
+		asserts.Nil(history[0].ChangedByID)
+	}
+	pending, err := testEmployeeService.FindPendingChanges(adminCtx, &dto.PendingChangeSearchRequest{ID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(pending, 1) {
+		asserts.Nil(pending[0].ScheduledByID)
+	}
+}
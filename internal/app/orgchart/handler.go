@@ -0,0 +1,60 @@
+package orgchart
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
+	pkgorgchart "github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/orgchart"
+	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(f *factory.Factory) *handler {
+	return &handler{
+		service: NewService(f),
+	}
+}
+
+// Get renders the org chart as a JSON tree, or as text that can be pasted into
+// documentation when format is dot or mermaid.
+func (h *handler) Get(c echo.Context) error {
+	if _, err := middleware.Principal(c); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	}
+
+	payload := new(dto.OrgChartRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	roots, err := h.service.Build(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	var b bytes.Buffer
+	switch payload.Format {
+	case "dot":
+		if err := pkgorgchart.RenderDOT(&b, roots); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err).Send(c)
+		}
+		return c.Blob(http.StatusOK, "text/vnd.graphviz; charset=utf-8", b.Bytes())
+	case "mermaid":
+		if err := pkgorgchart.RenderMermaid(&b, roots); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err).Send(c)
+		}
+		return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, b.Bytes())
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, roots, "Get org chart success", nil).Send(c)
+}
@@ -0,0 +1,100 @@
+package orgchart
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/mocks"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	db              = database.GetConnection()
+	echoMock        = mocks.EchoMock{E: echo.New()}
+	f               = factory.Factory{EmployeeRepository: repository.NewEmployeeRepository(db)}
+	orgChartHandler = NewHandler(&f)
+	userClaims      = util.CreateJWTClaims("devoncthomas@superrito.com", 2, uint(enum.User), 1)
+)
+
+func TestOrgChartHandlerGetUnauthorized(t *testing.T) {
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	c.SetPath("/api/v1/org-chart")
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(orgChartHandler.Get(c)) {
+		asserts.Equal(401, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
+
+func TestOrgChartHandlerGetInvalidFormat(t *testing.T) {
+	c, rec := echoMock.RequestMock(http.MethodGet, "/?format=svg", nil)
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetPath("/api/v1/org-chart")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(orgChartHandler.Get(c)) {
+		asserts.Equal(400, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "bad_request")
+	}
+}
+
+func TestOrgChartHandlerGetMermaid(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	c, rec := echoMock.RequestMock(http.MethodGet, "/?format=mermaid&root=1", nil)
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetPath("/api/v1/org-chart")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(orgChartHandler.Get(c)) {
+		asserts.Equal(200, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "flowchart TD")
+		asserts.Contains(body, "e1 --> e2")
+	}
+}
+
+func TestOrgChartHandlerGetJSON(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	token, err := util.CreateJWTToken(userClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetPath("/api/v1/org-chart")
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(orgChartHandler.Get(c)) {
+		asserts.Equal(200, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "Get org chart success")
+		asserts.Contains(body, `"reports"`)
+	}
+}
@@ -0,0 +1,12 @@
+package orgchart
+
+import (
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handler) Route(g *echo.Group) {
+	g.Use(middleware.JWTMiddleware())
+	g.GET("", h.Get, middleware.RequireScope(enum.EmployeesRead))
+}
@@ -0,0 +1,63 @@
+package orgchart
+
+import (
+	"context"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	pkgorgchart "github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/orgchart"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
+	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
+)
+
+type service struct {
+	EmployeeRepository repository.Employee
+}
+
+type Service interface {
+	Build(ctx context.Context, payload *dto.OrgChartRequest) ([]*pkgorgchart.Node, error)
+}
+
+func NewService(f *factory.Factory) Service {
+	return &service{
+		EmployeeRepository: f.EmployeeRepository,
+	}
+}
+
+// Build returns the reporting structure as trees. Division-scoped callers only
+// see the employees of their division.
+func (s *service) Build(ctx context.Context, payload *dto.OrgChartRequest) ([]*pkgorgchart.Node, error) {
+	var divisionID *uint
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		divisionID = principal.ScopeDivisionID
+	}
+	employees, err := s.EmployeeRepository.FindAllWithRelations(ctx, divisionID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	people := make([]pkgorgchart.Person, 0, len(employees))
+	for _, employee := range employees {
+		people = append(people, pkgorgchart.Person{
+			ID:        employee.ID,
+			ManagerID: employee.ManagerID,
+			Name:      employee.Fullname,
+			JobTitle:  employee.JobTitle,
+			Role:      employee.Role.Name,
+			Division: pkgorgchart.Division{
+				ID:   employee.Division.ID,
+				Name: employee.Division.Name,
+			},
+		})
+	}
+
+	roots, err := pkgorgchart.Build(people, payload.Root)
+	if err != nil {
+		if err == pkgorgchart.ErrRootNotFound {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return roots, nil
+}
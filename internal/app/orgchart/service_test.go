@@ -0,0 +1,73 @@
+package orgchart
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ctx             = context.Background()
+	orgChartService = NewService(factory.NewFactory())
+)
+
+func TestOrgChartServiceBuildSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	roots, err := orgChartService.Build(ctx, &dto.OrgChartRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(roots, 1) {
+		asserts.Equal(uint(1), roots[0].ID)
+		if asserts.Len(roots[0].Reports, 1) {
+			asserts.Equal(uint(2), roots[0].Reports[0].ID)
+			asserts.Len(roots[0].Reports[0].Reports, 1)
+		}
+	}
+}
+
+func TestOrgChartServiceBuildRootNotFound(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		root    = uint(10)
+	)
+	_, err := orgChartService.Build(ctx, &dto.OrgChartRequest{Root: &root})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 404")
+	}
+}
+
+func TestOrgChartServiceBuildDivisionScoped(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts    = assert.New(t)
+		divisionID = uint(2)
+		claims     = dto.JWTClaims{UserID: 3, DivisionID: 2, ScopeDivisionID: &divisionID}
+		scopedCtx  = auth.NewContext(ctx, auth.FromClaims(&claims))
+	)
+	// the manager of bettina is in another division, so she starts the chart
+	roots, err := orgChartService.Build(scopedCtx, &dto.OrgChartRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(roots, 1) {
+		asserts.Equal(uint(3), roots[0].ID)
+	}
+}
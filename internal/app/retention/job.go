@@ -0,0 +1,118 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
+	pkgutil "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	RETENTION_DAYS     int
+	RETENTION_INTERVAL time.Duration
+)
+
+func init() {
+	LoadConfig()
+}
+
+// LoadConfig reads the settings of the package from the environment. The
+// package loads them when it is initialised, before main has read .env, so
+// main loads them again once it has.
+func LoadConfig() {
+	RETENTION_DAYS = pkgutil.GetenvInt("RETENTION_DAYS", 0)
+	RETENTION_INTERVAL = pkgutil.GetenvDuration("RETENTION_INTERVAL", time.Duration(24)*time.Hour)
+}
+
+// purgeBatchSize bounds the records of each kind purged per run; the rest wait
+// for the next one.
+const purgeBatchSize = 100
+
+// Job purges employees, divisions and roles soft-deleted more than Days days
+// ago. Records still referred to by others are not picked until those are
+// purged as well, which may take another run.
+type Job struct {
+	EmployeeRepository repository.Employee
+	DivisionRepository repository.Division
+	RoleRepository     repository.Role
+	Days               int
+	Interval           time.Duration
+}
+
+func NewJob(f *factory.Factory) *Job {
+	return &Job{
+		EmployeeRepository: f.EmployeeRepository,
+		DivisionRepository: f.DivisionRepository,
+		RoleRepository:     f.RoleRepository,
+		Days:               RETENTION_DAYS,
+		Interval:           RETENTION_INTERVAL,
+	}
+}
+
+// Run purges every Interval until ctx is done. A non-positive Days or Interval
+// disables the job, keeping deleted records forever.
+func (j *Job) Run(ctx context.Context) {
+	if j.Days <= 0 || j.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := j.Purge(ctx, time.Now()); err != nil {
+			logrus.Error("purge deleted records: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes the records deleted more than Days days before now and
+// returns how many were removed. Employees go first, as they refer to their
+// division and role.
+func (j *Job) Purge(ctx context.Context, now time.Time) (int, error) {
+	before := now.AddDate(0, 0, -j.Days)
+
+	employees, err := purge(ctx, before, j.EmployeeRepository.FindDeletedBefore, j.EmployeeRepository.Purge)
+	if err != nil {
+		return employees, err
+	}
+	divisions, err := purge(ctx, before, j.DivisionRepository.FindDeletedBefore, j.DivisionRepository.Purge)
+	if err != nil {
+		return employees + divisions, err
+	}
+	roles, err := purge(ctx, before, j.RoleRepository.FindDeletedBefore, j.RoleRepository.Purge)
+	return employees + divisions + roles, err
+}
+
+func purge[T any](
+	ctx context.Context,
+	before time.Time,
+	find func(ctx context.Context, before time.Time, limit int) ([]T, error),
+	remove func(ctx context.Context, record *T) error,
+) (int, error) {
+	records, err := find(ctx, before, purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range records {
+		if err := remove(ctx, &records[i]); err != nil {
+			if errors.Is(err, repository.ErrInUse) {
+				continue
+			}
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,113 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobPurgeSuccess(t *testing.T) {
+	db := database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		ctx     = context.Background()
+		f       = factory.NewFactory()
+		job     = NewJob(f)
+		now     = time.Now()
+	)
+	job.Days = 30
+
+	// bettina was deleted long ago, HR only yesterday
+	if err := db.Model(&model.Employee{}).Where("id = ?", 3).Update("deleted_at", now.AddDate(0, 0, -31)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&model.Division{}).Where("id = ?", uint(enum.HR)).Update("deleted_at", now.AddDate(0, 0, -1)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := job.Purge(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(1, purged)
+
+	_, err = f.EmployeeRepository.FindByIDUnscoped(ctx, 3)
+	asserts.Error(err)
+	_, err = f.DivisionRepository.FindDeletedByID(ctx, uint(enum.HR))
+	asserts.NoError(err)
+}
+
+func TestJobPurgeInUse(t *testing.T) {
+	db := database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		ctx     = context.Background()
+		job     = NewJob(factory.NewFactory())
+		now     = time.Now()
+	)
+	job.Days = 30
+
+	// IT still has bettina
+	if err := db.Model(&model.Division{}).Where("id = ?", uint(enum.IT)).Update("deleted_at", now.AddDate(0, 0, -31)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := job.Purge(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(0, purged)
+}
+
+func TestJobPurgeSkipsInUse(t *testing.T) {
+	db := database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		ctx     = context.Background()
+		f       = factory.NewFactory()
+		job     = NewJob(f)
+		now     = time.Now()
+	)
+	job.Days = 30
+
+	// IT still has bettina and was deleted before HR
+	if err := db.Model(&model.Division{}).Where("id = ?", uint(enum.IT)).Update("deleted_at", now.AddDate(0, 0, -40)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&model.Division{}).Where("id = ?", uint(enum.HR)).Update("deleted_at", now.AddDate(0, 0, -31)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// a batch of one is not taken up by IT
+	divisions, err := f.DivisionRepository.FindDeletedBefore(ctx, now.AddDate(0, 0, -job.Days), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(divisions, 1) {
+		asserts.Equal(uint(enum.HR), divisions[0].ID)
+	}
+
+	purged, err := job.Purge(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(1, purged)
+	_, err = f.DivisionRepository.FindDeletedByID(ctx, uint(enum.IT))
+	asserts.NoError(err)
+}
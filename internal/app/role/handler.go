@@ -1,13 +1,10 @@
 package role
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
 	"github.com/labstack/echo/v4"
@@ -24,15 +21,7 @@ func NewHandler(f *factory.Factory) *handler {
 }
 
 func (h *handler) Get(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	if (err != nil) || (jwtClaims.RoleID != uint(enum.Admin)) {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
-	}
-
-	log.Println(jwtClaims)
-
-	payload := new(pkgdto.SearchGetRequest)
+	payload := new(dto.RoleSearchRequest)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
 	}
@@ -50,14 +39,6 @@ func (h *handler) Get(c echo.Context) error {
 }
 
 func (h *handler) GetById(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	if (err != nil) || (jwtClaims.RoleID != uint(enum.Admin)) {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
-	}
-
-	log.Println(jwtClaims)
-
 	payload := new(pkgdto.ByIDRequest)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
@@ -75,14 +56,6 @@ func (h *handler) GetById(c echo.Context) error {
 }
 
 func (h *handler) UpdateById(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	if (err != nil) || (jwtClaims.RoleID != uint(enum.Admin)) {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
-	}
-
-	log.Println(jwtClaims)
-
 	payload := new(dto.UpdateRoleRequestBody)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
@@ -99,14 +72,29 @@ func (h *handler) UpdateById(c echo.Context) error {
 }
 
 func (h *handler) DeleteById(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	if (err != nil) || (jwtClaims.RoleID != uint(enum.Admin)) {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+	payload := new(dto.DeleteRoleRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	var result *dto.RoleWithCUDResponse
+	var err error
+	if payload.Hard {
+		result, err = h.service.PurgeById(c.Request().Context(), &payload.ByIDRequest)
+	} else {
+		result, err = h.service.DeleteById(c.Request().Context(), payload)
+	}
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
 	}
 
-	log.Println(jwtClaims)
+	return res.SuccessResponse(result).Send(c)
+}
 
+func (h *handler) Restore(c echo.Context) error {
 	payload := new(pkgdto.ByIDRequest)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
@@ -114,7 +102,8 @@ func (h *handler) DeleteById(c echo.Context) error {
 	if err := c.Validate(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
 	}
-	result, err := h.service.DeleteById(c.Request().Context(), payload)
+
+	result, err := h.service.Restore(c.Request().Context(), payload)
 	if err != nil {
 		return res.ErrorResponse(err).Send(c)
 	}
@@ -123,14 +112,6 @@ func (h *handler) DeleteById(c echo.Context) error {
 }
 
 func (h *handler) Create(c echo.Context) error {
-	authHeader := c.Request().Header.Get("Authorization")
-	jwtClaims, err := util.ParseJWTToken(authHeader)
-	if (err != nil) || (jwtClaims.RoleID != uint(enum.Admin)) {
-		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
-	}
-
-	log.Println(jwtClaims)
-	
 	payload := new(dto.CreateRoleRequestBody)
 	if err := c.Bind(payload); err != nil {
 		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
@@ -146,3 +127,46 @@ func (h *handler) Create(c echo.Context) error {
 
 	return res.SuccessResponse(role).Send(c)
 }
+
+func (h *handler) GetPermissions(c echo.Context) error {
+	result, err := h.service.FindPermissions(c.Request().Context())
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, result, "Get permissions success", nil).Send(c)
+}
+
+func (h *handler) GetAncestors(c echo.Context) error {
+	payload := new(pkgdto.ByIDRequest)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.FindAncestors(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.CustomSuccessBuilder(http.StatusOK, result, "Get role ancestors success", nil).Send(c)
+}
+
+func (h *handler) UpdatePermissions(c echo.Context) error {
+	payload := new(dto.UpdateRolePermissionsRequestBody)
+	if err := c.Bind(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, err).Send(c)
+	}
+	if err := c.Validate(payload); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Validation, err).Send(c)
+	}
+
+	result, err := h.service.UpdatePermissions(c.Request().Context(), payload)
+	if err != nil {
+		return res.ErrorResponse(err).Send(c)
+	}
+
+	return res.SuccessResponse(result).Send(c)
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/mocks"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
@@ -21,10 +23,10 @@ import (
 )
 
 var (
-	adminClaims       = util.CreateJWTClaims(testEmail, testEmployeeID, testAdminRoleID, testDivisionID)
+	adminClaims       = util.CreateJWTClaims(testEmail, testEmployeeID, testAdminRoleID, testDivisionID, enum.PermissionNames()...)
 	db                = database.GetConnection()
 	echoMock          = mocks.EchoMock{E: echo.New()}
-	f                 = factory.Factory{RoleRepository: repository.NewRoleRepository(db)}
+	f                 = factory.Factory{RoleRepository: repository.NewRoleRepository(db), PermissionRepository: repository.NewPermissionRepository(db)}
 	roleHandler       = NewHandler(&f)
 	testAdminRoleID   = uint(enum.Admin)
 	testCreatePayload = dto.CreateRoleRequestBody{Name: &testRoleName}
@@ -50,7 +52,7 @@ func TestRoleHandlerGetInvalidPayload(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.Get(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesRead)(roleHandler.Get)(c)) {
 		asserts.Equal(400, rec.Code)
 
 		body := rec.Body.String()
@@ -69,7 +71,7 @@ func TestRoleHandlerGetUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.Get(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesRead)(roleHandler.Get)(c)) {
 		asserts.Equal(401, rec.Code)
 		body := rec.Body.String()
 		asserts.Contains(body, "unauthorized")
@@ -90,7 +92,7 @@ func TestRoleHandlerGetSuccess(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.Get(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesRead)(roleHandler.Get)(c)) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
@@ -115,7 +117,7 @@ func TestRoleHandlerGetByIdInvalidPayload(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.GetById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesRead)(roleHandler.GetById)(c)) {
 		asserts.Equal(400, rec.Code)
 
 		body := rec.Body.String()
@@ -140,7 +142,7 @@ func TestRoleHandlerGetByIdNotFound(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.GetById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesRead)(roleHandler.GetById)(c)) {
 		asserts.Equal(404, rec.Code)
 
 		body := rec.Body.String()
@@ -163,7 +165,7 @@ func TestRoleHandlerGetByIdUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.GetById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesRead)(roleHandler.GetById)(c)) {
 		asserts.Equal(401, rec.Code)
 		body := rec.Body.String()
 		asserts.Contains(body, "unauthorized")
@@ -188,7 +190,7 @@ func TestRoleHandlerGetByIdSuccess(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.GetById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesRead)(roleHandler.GetById)(c)) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
@@ -212,7 +214,7 @@ func TestRoleHandlerUpdateByIdInvalidPayload(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.UpdateById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.UpdateById)(c)) {
 		asserts.Equal(400, rec.Code)
 
 		body := rec.Body.String()
@@ -242,7 +244,7 @@ func TestRoleHandlerUpdateByIdNotFound(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.UpdateById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.UpdateById)(c)) {
 		asserts.Equal(404, rec.Code)
 
 		body := rec.Body.String()
@@ -264,7 +266,7 @@ func TestRoleHandlerUpdateByIdUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.UpdateById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.UpdateById)(c)) {
 		asserts.Equal(401, rec.Code)
 		body := rec.Body.String()
 		asserts.Contains(body, "unauthorized")
@@ -294,7 +296,7 @@ func TestRoleHandlerUpdateByIdSuccess(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.UpdateById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.UpdateById)(c)) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
@@ -318,7 +320,7 @@ func TestRoleHandlerDeleteByIdInvalidPayload(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.DeleteById)(c)) {
 		asserts.Equal(400, rec.Code)
 
 		body := rec.Body.String()
@@ -343,7 +345,7 @@ func TestRoleHandlerDeleteByIdNotFound(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.DeleteById)(c)) {
 		asserts.Equal(404, rec.Code)
 
 		body := rec.Body.String()
@@ -366,7 +368,7 @@ func TestRoleHandlerDeleteByIdUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.DeleteById)(c)) {
 		asserts.Equal(401, rec.Code)
 		body := rec.Body.String()
 		asserts.Contains(body, "unauthorized")
@@ -377,7 +379,7 @@ func TestRoleHandlerDeleteByIdSuccess(t *testing.T) {
 	seeder.NewSeeder().DeleteAll()
 	seeder.NewSeeder().SeedAll()
 
-	c, rec := echoMock.RequestMock(http.MethodDelete, "/", nil)
+	c, rec := echoMock.RequestMock(http.MethodDelete, fmt.Sprintf("/?reassign_to=%d", enum.User), nil)
 	roleID := strconv.Itoa(int(testAdminRoleID))
 	token, err := util.CreateJWTToken(adminClaims)
 	if err != nil {
@@ -391,7 +393,7 @@ func TestRoleHandlerDeleteByIdSuccess(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.DeleteById(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.DeleteById)(c)) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
@@ -419,7 +421,7 @@ func TestRoleHandlerCreateInvalidPayload(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.Create(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.Create)(c)) {
 		asserts.Equal(400, rec.Code)
 
 		body := rec.Body.String()
@@ -449,7 +451,7 @@ func TestRoleHandlerCreateRoleAlreadyExist(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.Create(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.Create)(c)) {
 		asserts.Equal(409, rec.Code)
 
 		body := rec.Body.String()
@@ -475,7 +477,7 @@ func TestRoleHandlerCreateUnauthorized(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.Create(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.Create)(c)) {
 		asserts.Equal(401, rec.Code)
 
 		body := rec.Body.String()
@@ -503,7 +505,7 @@ func TestRoleHandlerCreateSuccess(t *testing.T) {
 
 	// testing
 	asserts := assert.New(t)
-	if asserts.NoError(roleHandler.Create(c)) {
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.Create)(c)) {
 		asserts.Equal(200, rec.Code)
 
 		body := rec.Body.String()
@@ -511,3 +513,88 @@ func TestRoleHandlerCreateSuccess(t *testing.T) {
 		asserts.Contains(body, "name")
 	}
 }
+
+func TestRoleHandlerUpdatePermissionsUnauthorized(t *testing.T) {
+	token, err := util.CreateJWTToken(util.CreateJWTClaims(testEmail, testEmployeeID, testAdminRoleID, testDivisionID, string(enum.RolesRead)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPut, "/", nil)
+	c.SetPath("/api/v1/roles/:id/permissions")
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(testUserRoleID)))
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing: roles:read alone does not allow granting permissions
+	asserts := assert.New(t)
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.UpdatePermissions)(c)) {
+		asserts.Equal(401, rec.Code)
+		body := rec.Body.String()
+		asserts.Contains(body, "unauthorized")
+	}
+}
+
+func TestRoleHandlerUpdatePermissionsSuccess(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	payload, err := json.Marshal(dto.UpdateRolePermissionsRequestBody{Permissions: []string{string(enum.RolesRead)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, rec := echoMock.RequestMock(http.MethodPut, "/", bytes.NewBuffer(payload))
+	token, err := util.CreateJWTToken(adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/roles/:id/permissions")
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(testUserRoleID)))
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	c.Request().Header.Set("Content-Type", "application/json")
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(middleware.RequirePermission(enum.RolesWrite)(roleHandler.UpdatePermissions)(c)) {
+		asserts.Equal(200, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, string(enum.RolesRead))
+	}
+}
+
+func TestRoleHandlerGetAncestorsSuccess(t *testing.T) {
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		name     = "Super Admin"
+		parentID = testAdminRoleID
+	)
+	role, err := roleService.Store(auth.NewContext(ctx, auth.FromClaims(&adminClaims)), &dto.CreateRoleRequestBody{Name: &name, ParentID: &parentID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, rec := echoMock.RequestMock(http.MethodGet, "/", nil)
+	token, err := util.CreateJWTToken(adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetPath("/api/v1/roles/:id/ancestors")
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(role.ID)))
+	c.Request().Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	// testing
+	asserts := assert.New(t)
+	if asserts.NoError(middleware.RequirePermission(enum.RolesRead)(roleHandler.GetAncestors)(c)) {
+		asserts.Equal(200, rec.Code)
+
+		body := rec.Body.String()
+		asserts.Contains(body, "Get role ancestors success")
+		asserts.Contains(body, `"name":"Admin"`)
+	}
+}
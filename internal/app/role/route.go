@@ -1,17 +1,20 @@
 package role
 
 import (
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	"github.com/labstack/echo/v4"
 )
 
 func (h *handler) Route(g *echo.Group) {
-	g.Use(middleware.JWTMiddleware(dto.JWTClaims{}, util.JWT_SECRET))
-	g.GET("", h.Get)
-	g.GET("/:id", h.GetById)
-	g.PUT("/:id", h.UpdateById)
-	g.DELETE("/:id", h.DeleteById)
-	g.POST("", h.Create)
+	g.Use(middleware.JWTMiddleware())
+	g.GET("", h.Get, middleware.RequirePermission(enum.RolesRead))
+	g.GET("/permissions", h.GetPermissions, middleware.RequirePermission(enum.RolesRead))
+	g.GET("/:id", h.GetById, middleware.RequirePermission(enum.RolesRead))
+	g.GET("/:id/ancestors", h.GetAncestors, middleware.RequirePermission(enum.RolesRead))
+	g.PUT("/:id", h.UpdateById, middleware.RequirePermission(enum.RolesWrite))
+	g.PUT("/:id/permissions", h.UpdatePermissions, middleware.RequirePermission(enum.RolesWrite))
+	g.DELETE("/:id", h.DeleteById, middleware.RequirePermission(enum.RolesWrite), middleware.DenyImpersonation())
+	g.POST("/:id/restore", h.Restore, middleware.RequirePermission(enum.RolesWrite), middleware.DenyImpersonation())
+	g.POST("", h.Create, middleware.RequirePermission(enum.RolesWrite))
 }
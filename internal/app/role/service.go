@@ -3,9 +3,16 @@ package role
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/constant"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
@@ -13,24 +20,39 @@ import (
 )
 
 type service struct {
-	RoleRepository repository.Role
+	RoleRepository       repository.Role
+	PermissionRepository repository.Permission
 }
 
 type Service interface {
-	Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*pkgdto.SearchGetResponse[dto.RoleResponse], error)
+	Find(ctx context.Context, payload *dto.RoleSearchRequest) (*pkgdto.SearchGetResponse[dto.RoleResponse], error)
 	FindByID(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.RoleResponse, error)
 	Store(ctx context.Context, payload *dto.CreateRoleRequestBody) (*dto.RoleResponse, error)
 	UpdateById(ctx context.Context, payload *dto.UpdateRoleRequestBody) (*dto.RoleResponse, error)
-	DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.RoleWithCUDResponse, error)
+	DeleteById(ctx context.Context, payload *dto.DeleteRoleRequest) (*dto.RoleWithCUDResponse, error)
+	PurgeById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.RoleWithCUDResponse, error)
+	Restore(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.RoleResponse, error)
+	FindPermissions(ctx context.Context) ([]dto.PermissionResponse, error)
+	UpdatePermissions(ctx context.Context, payload *dto.UpdateRolePermissionsRequestBody) (*dto.RoleResponse, error)
+	FindAncestors(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.RoleResponse, error)
+	Lineage(ctx context.Context, roleID uint) ([]uint, error)
+	ResolvePermissions(ctx context.Context, roleID uint) ([]string, error)
 }
 
 func NewService(f *factory.Factory) Service {
 	return &service{
-		RoleRepository: f.RoleRepository,
+		RoleRepository:       f.RoleRepository,
+		PermissionRepository: f.PermissionRepository,
 	}
 }
 
-func (s *service) Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*pkgdto.SearchGetResponse[dto.RoleResponse], error) {
+// Find lists deleted roles only to callers who may restore them.
+func (s *service) Find(ctx context.Context, payload *dto.RoleSearchRequest) (*pkgdto.SearchGetResponse[dto.RoleResponse], error) {
+	if payload.DeletedFilter.Any() {
+		if err := auth.Authorize(ctx, auth.Permission(enum.RolesWrite)); err != nil {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+		}
+	}
 	roles, info, err := s.RoleRepository.FindAll(ctx, payload, &payload.Pagination)
 	if err != nil {
 		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
@@ -40,8 +62,10 @@ func (s *service) Find(ctx context.Context, payload *pkgdto.SearchGetRequest) (*
 
 	for _, role := range roles {
 		data = append(data, dto.RoleResponse{
-			ID:   role.ID,
-			Name: role.Name,
+			ID:          role.ID,
+			Name:        role.Name,
+			ParentID:    role.ParentID,
+			Permissions: role.PermissionNames(),
 		})
 
 	}
@@ -64,10 +88,14 @@ func (s *service) FindByID(ctx context.Context, payload *pkgdto.ByIDRequest) (*d
 
 	result.ID = data.ID
 	result.Name = data.Name
+	result.ParentID = data.ParentID
+	result.Permissions = data.PermissionNames()
 
 	return &result, nil
 }
 
+// Store creates the role. A role inherits every permission of its parent, so
+// callers can only give it a parent whose permissions they hold themselves.
 func (s *service) Store(ctx context.Context, payload *dto.CreateRoleRequestBody) (*dto.RoleResponse, error) {
 	var result dto.RoleResponse
 	isExist, err := s.RoleRepository.ExistByName(ctx, *payload.Name)
@@ -77,18 +105,38 @@ func (s *service) Store(ctx context.Context, payload *dto.CreateRoleRequestBody)
 	if isExist {
 		return &result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("role already exists"))
 	}
+	if payload.ParentID != nil && *payload.ParentID == 0 {
+		payload.ParentID = nil
+	}
+	if payload.ParentID != nil {
+		if err := s.checkParent(ctx, 0, *payload.ParentID); err != nil {
+			return &result, err
+		}
+		if err := s.authorizeParent(ctx, *payload.ParentID); err != nil {
+			return &result, err
+		}
+	}
 
 	data, err := s.RoleRepository.Save(ctx, payload)
 	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return &result, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
 		return &result, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
 
 	result.ID = data.ID
 	result.Name = data.Name
+	result.ParentID = data.ParentID
+	result.Permissions = data.PermissionNames()
 
 	return &result, nil
 }
 
+// UpdateById saves the changes to the role. Moving the role under another
+// parent takes the permissions of that parent, and revokes the tokens of the
+// employees holding the role or a role inheriting from it, as they carry the
+// permissions inherited from the old parent.
 func (s *service) UpdateById(ctx context.Context, payload *dto.UpdateRoleRequestBody) (*dto.RoleResponse, error) {
 	role, err := s.RoleRepository.FindByID(ctx, *payload.ID)
 	if err != nil {
@@ -97,18 +145,47 @@ func (s *service) UpdateById(ctx context.Context, payload *dto.UpdateRoleRequest
 		}
 		return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
+	parentChanged := false
+	if payload.ParentID != nil {
+		if *payload.ParentID == 0 {
+			parentChanged = role.ParentID != nil
+		} else {
+			parentChanged = role.ParentID == nil || *role.ParentID != *payload.ParentID
+		}
+	}
+	if parentChanged && *payload.ParentID != 0 {
+		if err := s.checkParent(ctx, role.ID, *payload.ParentID); err != nil {
+			return &dto.RoleResponse{}, err
+		}
+		if err := s.authorizeParent(ctx, *payload.ParentID); err != nil {
+			return &dto.RoleResponse{}, err
+		}
+	}
 
 	_, err = s.RoleRepository.Edit(ctx, &role, payload)
 	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
 		return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
+	if parentChanged {
+		if err := s.revokeHolders(ctx, role.ID); err != nil {
+			return &dto.RoleResponse{}, err
+		}
+	}
 	var result dto.RoleResponse
 	result.ID = role.ID
 	result.Name = role.Name
+	result.ParentID = role.ParentID
+	result.Permissions = role.PermissionNames()
 
 	return &result, nil
 }
-func (s *service) DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.RoleWithCUDResponse, error) {
+
+// DeleteById refuses to delete a role that employees, pending changes or
+// child roles still refer to unless they are given payload.ReassignTo.
+func (s *service) DeleteById(ctx context.Context, payload *dto.DeleteRoleRequest) (*dto.RoleWithCUDResponse, error) {
 	role, err := s.RoleRepository.FindByID(ctx, payload.ID)
 	if err != nil {
 		if err == constant.RECORD_NOT_FOUND {
@@ -116,15 +193,30 @@ func (s *service) DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (
 		}
 		return &dto.RoleWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
-	_, err = s.RoleRepository.Destroy(ctx, &role)
+	usage, err := s.RoleRepository.FindUsage(ctx, role.ID)
 	if err != nil {
 		return &dto.RoleWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
 	}
 
+	switch {
+	case payload.ReassignTo != nil:
+		if err := s.reassignAndDestroy(ctx, &role, *payload.ReassignTo, usage); err != nil {
+			return &dto.RoleWithCUDResponse{}, err
+		}
+	case usage.Employees > 0 || usage.PendingChanges > 0 || usage.ChildRoles > 0:
+		return &dto.RoleWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.Conflict, fmt.Errorf("role is held by %d employees and %d pending changes and is the parent of %d roles, give reassign_to to move them", usage.Employees, usage.PendingChanges, usage.ChildRoles))
+	default:
+		if _, err := s.RoleRepository.Destroy(ctx, &role); err != nil {
+			return &dto.RoleWithCUDResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+
 	result := &dto.RoleWithCUDResponse{
 		RoleResponse: dto.RoleResponse{
-			ID:   role.ID,
-			Name: role.Name,
+			ID:          role.ID,
+			Name:        role.Name,
+			ParentID:    role.ParentID,
+			Permissions: role.PermissionNames(),
 		},
 		CreatedAt: role.CreatedAt,
 		UpdatedAt: role.UpdatedAt,
@@ -133,3 +225,320 @@ func (s *service) DeleteById(ctx context.Context, payload *pkgdto.ByIDRequest) (
 
 	return result, nil
 }
+
+// reassignAndDestroy gives the target role to the employees holding the role,
+// makes it the parent of the roles inheriting from it and deletes it. Moving
+// employees takes the same rights as assigning them a role, and moving child
+// roles the same rights as setting their parent. The tokens of the employees
+// holding the role or a role inheriting from it are revoked as they carry the
+// old permissions. The target cannot inherit from the role, as its child
+// roles would then inherit from themselves.
+func (s *service) reassignAndDestroy(ctx context.Context, role *model.Role, targetID uint, usage model.RoleUsage) error {
+	if targetID == role.ID {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("role cannot be reassigned to itself"))
+	}
+	if _, err := s.RoleRepository.FindByID(ctx, targetID); err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("reassign_to role not found"))
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if usage.Employees > 0 || usage.PendingChanges > 0 || usage.ChildRoles > 0 {
+		permissions, err := s.ResolvePermissions(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		policies := []auth.Policy{auth.NotImpersonating(), auth.Unscoped(), auth.Includes(permissions, nil)}
+		if usage.Employees > 0 || usage.PendingChanges > 0 {
+			policies = append(policies, auth.Permission(enum.EmployeesWrite))
+		}
+		if err := auth.Authorize(ctx, policies...); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+		}
+	}
+	ancestors, err := s.RoleRepository.FindAncestors(ctx, targetID)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.ID == role.ID {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, fmt.Errorf("role %d inherits from role %d", targetID, role.ID))
+		}
+	}
+
+	holders, err := s.RoleRepository.FindHolders(ctx, role.ID)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if _, err := s.RoleRepository.ReassignAndDestroy(ctx, role, targetID); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	for _, id := range holders {
+		if err := util.RevocationStore.RevokeEmployee(ctx, id, time.Now()); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+	return nil
+}
+
+// PurgeById removes the role for good, whether it was soft-deleted before or
+// not. Roles still held by employees or inherited by other roles are kept.
+func (s *service) PurgeById(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.RoleWithCUDResponse, error) {
+	if err := auth.Authorize(ctx, auth.Permission(enum.RecordsPurge), auth.NotImpersonating(), auth.Unscoped()); err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	role, err := s.RoleRepository.FindByIDUnscoped(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := s.RoleRepository.Purge(ctx, &role); err != nil {
+		if errors.Is(err, repository.ErrInUse) {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Conflict, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := &dto.RoleWithCUDResponse{
+		RoleResponse: dto.RoleResponse{
+			ID:          role.ID,
+			Name:        role.Name,
+			ParentID:    role.ParentID,
+			Permissions: role.PermissionNames(),
+		},
+		CreatedAt: role.CreatedAt,
+		UpdatedAt: role.UpdatedAt,
+		DeletedAt: role.DeletedAt,
+	}
+	return result, nil
+}
+
+// Restore undeletes the role with the permissions it had, unless its name was
+// taken in the meantime or its parent is still deleted.
+func (s *service) Restore(ctx context.Context, payload *pkgdto.ByIDRequest) (*dto.RoleResponse, error) {
+	role, err := s.RoleRepository.FindDeletedByID(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	isExist, err := s.RoleRepository.ExistByName(ctx, role.Name)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if isExist {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, errors.New("role name already exists"))
+	}
+	if role.ParentID != nil {
+		if _, err := s.RoleRepository.FindByID(ctx, *role.ParentID); err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				return nil, res.ErrorBuilder(&res.ErrorConstant.Conflict, errors.New("parent role is deleted"))
+			}
+			return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+
+	if _, err := s.RoleRepository.Restore(ctx, &role); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.Duplicate, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	return &dto.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		ParentID:    role.ParentID,
+		Permissions: role.PermissionNames(),
+	}, nil
+}
+
+func (s *service) FindPermissions(ctx context.Context) ([]dto.PermissionResponse, error) {
+	permissions, err := s.PermissionRepository.FindAll(ctx)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := make([]dto.PermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		result = append(result, dto.PermissionResponse{
+			Name:        permission.Name,
+			Description: permission.Description,
+		})
+	}
+
+	return result, nil
+}
+
+// UpdatePermissions replaces the permissions granted to a role. Callers can
+// only grant permissions they hold themselves. The tokens of the employees
+// holding the role or a role inheriting from it are revoked, as they carry
+// the old permissions.
+func (s *service) UpdatePermissions(ctx context.Context, payload *dto.UpdateRolePermissionsRequestBody) (*dto.RoleResponse, error) {
+	names := make([]string, 0, len(payload.Permissions))
+	seen := make(map[string]bool)
+	for _, name := range payload.Permissions {
+		if !enum.Permission(name).IsValid() {
+			return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.Validation, fmt.Errorf("unknown permission %s", name))
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if err := auth.Authorize(ctx, auth.Includes(names, nil), auth.NotImpersonating(), auth.Unscoped()); err != nil {
+		return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+
+	role, err := s.RoleRepository.FindByID(ctx, *payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	permissions, err := s.PermissionRepository.FindByNames(ctx, names)
+	if err != nil {
+		return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if len(permissions) != len(names) {
+		return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, errors.New("permissions are not migrated"))
+	}
+
+	if err := s.RoleRepository.ReplacePermissions(ctx, &role, permissions); err != nil {
+		return &dto.RoleResponse{}, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	if err := s.revokeHolders(ctx, role.ID); err != nil {
+		return &dto.RoleResponse{}, err
+	}
+
+	return &dto.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		ParentID:    role.ParentID,
+		Permissions: role.PermissionNames(),
+	}, nil
+}
+
+// FindAncestors lists the roles a role inherits permissions from, the nearest
+// first.
+func (s *service) FindAncestors(ctx context.Context, payload *pkgdto.ByIDRequest) ([]dto.RoleResponse, error) {
+	ancestors, err := s.RoleRepository.FindAncestors(ctx, payload.ID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	result := make([]dto.RoleResponse, 0, len(ancestors))
+	for _, role := range ancestors {
+		result = append(result, dto.RoleResponse{
+			ID:          role.ID,
+			Name:        role.Name,
+			ParentID:    role.ParentID,
+			Permissions: role.PermissionNames(),
+		})
+	}
+
+	return result, nil
+}
+
+// Lineage returns the ID of the role followed by the IDs of its ancestors. A
+// role satisfies every check satisfied by a role of its lineage.
+func (s *service) Lineage(ctx context.Context, roleID uint) ([]uint, error) {
+	ancestors, err := s.RoleRepository.FindAncestors(ctx, roleID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	lineage := []uint{roleID}
+	for _, role := range ancestors {
+		lineage = append(lineage, role.ID)
+	}
+	return lineage, nil
+}
+
+// ResolvePermissions returns the names of the permissions granted to the role
+// or inherited from its ancestors, sorted and without duplicates.
+func (s *service) ResolvePermissions(ctx context.Context, roleID uint) ([]string, error) {
+	role, err := s.RoleRepository.FindByID(ctx, roleID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return nil, res.ErrorBuilder(&res.ErrorConstant.NotFound, err)
+		}
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	ancestors, err := s.RoleRepository.FindAncestors(ctx, roleID)
+	if err != nil {
+		return nil, res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, r := range append([]model.Role{role}, ancestors...) {
+		for _, name := range r.PermissionNames() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// authorizeParent requires the caller to hold every permission a role would
+// inherit from the parent.
+func (s *service) authorizeParent(ctx context.Context, parentID uint) error {
+	permissions, err := s.ResolvePermissions(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, auth.Includes(permissions, nil), auth.NotImpersonating(), auth.Unscoped()); err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err)
+	}
+	return nil
+}
+
+// revokeHolders revokes the tokens of the employees holding the role or a role
+// inheriting from it, as they carry the permissions of the role.
+func (s *service) revokeHolders(ctx context.Context, roleID uint) error {
+	holders, err := s.RoleRepository.FindHolders(ctx, roleID)
+	if err != nil {
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	for _, id := range holders {
+		if err := util.RevocationStore.RevokeEmployee(ctx, id, time.Now()); err != nil {
+			return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+		}
+	}
+	return nil
+}
+
+// checkParent rejects a parent that does not exist, or that would make the
+// role its own ancestor. roleID is 0 for a role not created yet.
+func (s *service) checkParent(ctx context.Context, roleID, parentID uint) error {
+	if parentID == roleID {
+		return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("role cannot be its own parent"))
+	}
+	ancestors, err := s.RoleRepository.FindAncestors(ctx, parentID)
+	if err != nil {
+		if err == constant.RECORD_NOT_FOUND {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, errors.New("parent role not found"))
+		}
+		return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err)
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.ID == roleID {
+			return res.ErrorBuilder(&res.ErrorConstant.BadRequest, fmt.Errorf("role %d already inherits from role %d", parentID, roleID))
+		}
+	}
+	return nil
+}
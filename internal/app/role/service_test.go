@@ -8,7 +8,9 @@ import (
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	"github.com/stretchr/testify/assert"
 )
@@ -25,7 +27,7 @@ func TestRoleServiceFindAllSuccess(t *testing.T) {
 
 	var (
 		asserts = assert.New(t)
-		payload = pkgdto.SearchGetRequest{}
+		payload = dto.RoleSearchRequest{}
 	)
 
 	res, err := roleService.Find(ctx, &payload)
@@ -119,18 +121,103 @@ func TestRoleServiceDeleteByIdSuccess(t *testing.T) {
 	seeder.NewSeeder().SeedAll()
 
 	var (
-		asserts = assert.New(t)
-		id      = uint(1)
-		payload = pkgdto.ByIDRequest{ID: id}
+		asserts    = assert.New(t)
+		adminCtx   = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+		reassignTo = uint(enum.User)
+		payload    = dto.DeleteRoleRequest{ReassignTo: &reassignTo}
 	)
+	payload.ID = uint(enum.Admin)
 
-	res, err := roleService.DeleteById(ctx, &payload)
+	res, err := roleService.DeleteById(adminCtx, &payload)
 	if err != nil {
 		t.Fatal(err)
 	}
 	asserts.NotNil(res.DeletedAt)
 }
 
+func TestRoleServiceDeleteByIdInUse(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+	payload := dto.DeleteRoleRequest{}
+	payload.ID = uint(enum.User)
+
+	_, err := roleService.DeleteById(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+}
+
+func TestRoleServiceDeleteByIdReassignChildRoles(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		adminCtx = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+		userID   = uint(enum.User)
+		adminID  = uint(enum.Admin)
+		name     = "Auditor"
+	)
+	auditor, err := roleService.Store(adminCtx, &dto.CreateRoleRequestBody{Name: &name, ParentID: &userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the target cannot inherit from the deleted role
+	payload := dto.DeleteRoleRequest{ReassignTo: &auditor.ID}
+	payload.ID = userID
+	_, err = roleService.DeleteById(adminCtx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+
+	payload.ReassignTo = &adminID
+	if _, err := roleService.DeleteById(adminCtx, &payload); err != nil {
+		t.Fatal(err)
+	}
+	res, err := roleService.FindByID(ctx, &pkgdto.ByIDRequest{ID: auditor.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(&adminID, res.ParentID)
+}
+
+func TestRoleServiceDeleteByIdReassignChildRolesEscalation(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		adminCtx = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+		claims   = util.CreateJWTClaims(testEmail, 2, testUserRoleID, testDivisionID, string(enum.RolesRead), string(enum.RolesWrite))
+		userCtx  = auth.NewContext(ctx, auth.FromClaims(&claims))
+		userID   = uint(enum.User)
+		adminID  = uint(enum.Admin)
+		name     = "Team Lead"
+		child    = "Auditor"
+	)
+	// Team Lead is held by nobody, Auditor inherits from it
+	teamLead, err := roleService.Store(adminCtx, &dto.CreateRoleRequestBody{Name: &name, ParentID: &userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := roleService.Store(adminCtx, &dto.CreateRoleRequestBody{Name: &child, ParentID: &teamLead.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := dto.DeleteRoleRequest{ReassignTo: &adminID}
+	payload.ID = teamLead.ID
+	_, err = roleService.DeleteById(userCtx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
 func TestRoleServiceDeleteByIdRecordNotFound(t *testing.T) {
 	database.GetConnection()
 	seeder.NewSeeder().DeleteAll()
@@ -138,7 +225,7 @@ func TestRoleServiceDeleteByIdRecordNotFound(t *testing.T) {
 	var (
 		asserts = assert.New(t)
 		id      = uint(10)
-		payload = pkgdto.ByIDRequest{ID: id}
+		payload = dto.DeleteRoleRequest{DeleteRequest: pkgdto.DeleteRequest{ByIDRequest: pkgdto.ByIDRequest{ID: id}}}
 	)
 
 	_, err := roleService.DeleteById(ctx, &payload)
@@ -185,3 +272,248 @@ func TestRoleServiceCreateRoleAlreadyExist(t *testing.T) {
 		asserts.Equal(err.Error(), "error code 409")
 	}
 }
+
+func TestRoleServiceUpdatePermissionsSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		adminCtx = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+		id       = uint(enum.User)
+		payload  = dto.UpdateRolePermissionsRequestBody{
+			ID:          &id,
+			Permissions: []string{string(enum.RolesRead), string(enum.RolesRead), string(enum.DivisionsWrite)},
+		}
+	)
+
+	res, err := roleService.UpdatePermissions(adminCtx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.ElementsMatch([]string{string(enum.RolesRead), string(enum.DivisionsWrite)}, res.Permissions)
+
+	role, err := roleService.FindByID(ctx, &pkgdto.ByIDRequest{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.ElementsMatch(res.Permissions, role.Permissions)
+}
+
+func TestRoleServiceUpdatePermissionsEscalation(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		claims  = util.CreateJWTClaims(testEmail, 2, testUserRoleID, testDivisionID, string(enum.RolesRead), string(enum.RolesWrite))
+		userCtx = auth.NewContext(ctx, auth.FromClaims(&claims))
+		id      = uint(enum.User)
+		payload = dto.UpdateRolePermissionsRequestBody{
+			ID:          &id,
+			Permissions: []string{string(enum.RolesWrite), string(enum.RecordsPurge)},
+		}
+	)
+
+	_, err := roleService.UpdatePermissions(userCtx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestRoleServiceUpdatePermissionsUnknownPermission(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts = assert.New(t)
+		id      = uint(enum.User)
+		payload = dto.UpdateRolePermissionsRequestBody{ID: &id, Permissions: []string{"everything"}}
+	)
+
+	_, err := roleService.UpdatePermissions(ctx, &payload)
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestRoleServiceFindPermissionsSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	asserts := assert.New(t)
+
+	res, err := roleService.FindPermissions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asserts.Len(res, len(enum.Permissions))
+	for _, val := range res {
+		asserts.NotEmpty(val.Description)
+	}
+}
+
+func TestRoleServiceCreateRoleWithParentInheritsPermissions(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		adminCtx = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+		name     = "Super Admin"
+		parentID = uint(enum.Admin)
+	)
+
+	role, err := roleService.Store(adminCtx, &dto.CreateRoleRequestBody{Name: &name, ParentID: &parentID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(&parentID, role.ParentID)
+	asserts.Empty(role.Permissions)
+
+	permissions, err := roleService.ResolvePermissions(ctx, role.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.ElementsMatch(enum.PermissionNames(), permissions)
+
+	ancestors, err := roleService.FindAncestors(ctx, &pkgdto.ByIDRequest{ID: role.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asserts.Len(ancestors, 1) {
+		asserts.Equal(uint(enum.Admin), ancestors[0].ID)
+	}
+
+	lineage, err := roleService.Lineage(ctx, role.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal([]uint{role.ID, uint(enum.Admin)}, lineage)
+}
+
+func TestRoleServiceCreateRoleParentNotFound(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		name     = "Super Admin"
+		parentID = uint(10)
+	)
+
+	_, err := roleService.Store(ctx, &dto.CreateRoleRequestBody{Name: &name, ParentID: &parentID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestRoleServiceParentEscalation(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		claims   = util.CreateJWTClaims(testEmail, 2, testUserRoleID, testDivisionID, string(enum.RolesRead), string(enum.RolesWrite))
+		userCtx  = auth.NewContext(ctx, auth.FromClaims(&claims))
+		name     = "Super Admin"
+		userName = "User"
+		userID   = uint(enum.User)
+		parentID = uint(enum.Admin)
+	)
+
+	_, err := roleService.Store(userCtx, &dto.CreateRoleRequestBody{Name: &name, ParentID: &parentID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+	// the caller cannot put their own role under Admin either
+	_, err = roleService.UpdateById(userCtx, &dto.UpdateRoleRequestBody{ID: &userID, Name: &userName, ParentID: &parentID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 401")
+	}
+}
+
+func TestRoleServiceUpdateByIdRejectsCycle(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		adminCtx = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+		name     = "Super Admin"
+		parentID = uint(enum.Admin)
+	)
+	role, err := roleService.Store(adminCtx, &dto.CreateRoleRequestBody{Name: &name, ParentID: &parentID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adminName := "Admin"
+	_, err = roleService.UpdateById(ctx, &dto.UpdateRoleRequestBody{ID: &parentID, Name: &adminName, ParentID: &role.ID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+	_, err = roleService.UpdateById(ctx, &dto.UpdateRoleRequestBody{ID: &role.ID, Name: &name, ParentID: &role.ID})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 400")
+	}
+}
+
+func TestRoleServiceRestoreSuccess(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		name     = "Auditor"
+		adminCtx = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+	)
+	role, err := roleService.Store(ctx, &dto.CreateRoleRequestBody{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := pkgdto.ByIDRequest{ID: role.ID}
+	if _, err := roleService.DeleteById(ctx, &dto.DeleteRoleRequest{DeleteRequest: pkgdto.DeleteRequest{ByIDRequest: payload}}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := roleService.Find(adminCtx, &dto.RoleSearchRequest{DeletedFilter: pkgdto.DeletedFilter{IncludeDeleted: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Len(all.Data, 3)
+
+	res, err := roleService.Restore(ctx, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.Equal(name, res.Name)
+
+	_, err = roleService.FindByID(ctx, &payload)
+	asserts.NoError(err)
+}
+
+func TestRoleServicePurgeByIdInUse(t *testing.T) {
+	database.GetConnection()
+	seeder.NewSeeder().DeleteAll()
+	seeder.NewSeeder().SeedAll()
+
+	var (
+		asserts  = assert.New(t)
+		adminCtx = auth.NewContext(ctx, auth.FromClaims(&adminClaims))
+	)
+	_, err := roleService.PurgeById(adminCtx, &pkgdto.ByIDRequest{ID: uint(enum.User)})
+	if asserts.Error(err) {
+		asserts.Equal(err.Error(), "error code 409")
+	}
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type (
+	CreateAPIKeyRequestBody struct {
+		Name   string   `json:"name" validate:"required"`
+		Scopes []string `json:"scopes" validate:"required,min=1"`
+	}
+	APIKeyResponse struct {
+		ID         uint       `json:"id"`
+		Name       string     `json:"name"`
+		Prefix     string     `json:"prefix"`
+		Scopes     []string   `json:"scopes"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+		RevokedAt  *time.Time `json:"revoked_at"`
+		CreatedAt  time.Time  `json:"created_at"`
+	}
+	APIKeyWithSecretResponse struct {
+		APIKeyResponse
+		Key string `json:"key"`
+	}
+)
@@ -1,33 +1,127 @@
 package dto
 
-import "github.com/golang-jwt/jwt/v4"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
 
 type (
+	// RegisterEmployeeRequestBody is used for self-registration, which always
+	// gets the User role.
 	RegisterEmployeeRequestBody struct {
 		Fullname   string `json:"fullname" validate:"required"`
 		Email      string `json:"email" validate:"required,email"`
 		Password   string `json:"password" validate:"required"`
-		RoleID     *uint  `json:"role_id"`
 		DivisionID *uint  `json:"division_id" validate:"required"`
+		EmployeeProfileRequestBody
 	}
 
 	ByEmailAndPasswordRequest struct {
-		Email    string `json:"email" validate:"required,email"`
+		Email     string `json:"email" validate:"required,email"`
+		Password  string `json:"password" validate:"required"`
+		IPAddress string `json:"-"`
+	}
+
+	RefreshTokenRequestBody struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+	}
+
+	LogoutRequestBody struct {
+		RefreshToken *string `json:"refresh_token"`
+	}
+
+	ForgotPasswordRequestBody struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	ResetPasswordRequestBody struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required"`
+	}
+
+	AcceptInvitationRequestBody struct {
+		Token    string `json:"token" validate:"required"`
 		Password string `json:"password" validate:"required"`
 	}
 
+	ImpersonateRequestBody struct {
+		ID     uint   `param:"id" validate:"required"`
+		Reason string `json:"reason" validate:"required"`
+	}
+
+	ImpersonationResponse struct {
+		EmployeeResponse
+		JWT       string    `json:"jwt"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	ImpersonationSessionResponse struct {
+		ID         uint       `json:"id"`
+		ActorID    uint       `json:"actor_id"`
+		EmployeeID uint       `json:"employee_id"`
+		Reason     string     `json:"reason"`
+		ExpiresAt  time.Time  `json:"expires_at"`
+		EndedAt    *time.Time `json:"ended_at"`
+		CreatedAt  time.Time  `json:"created_at"`
+	}
+
+	MFACodeRequestBody struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	MFAVerifyRequestBody struct {
+		MFAToken string `json:"mfa_token" validate:"required"`
+		Code     string `json:"code" validate:"required"`
+	}
+
+	MFAEnrollResponse struct {
+		Secret        string   `json:"secret"`
+		OTPAuthURI    string   `json:"otpauth_uri"`
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	IntrospectRequestBody struct {
+		Token         string `json:"token" form:"token" validate:"required"`
+		TokenTypeHint string `json:"token_type_hint" form:"token_type_hint"`
+	}
+
+	// IntrospectResponse follows RFC 7662. Every field but Active is omitted for
+	// inactive tokens.
+	IntrospectResponse struct {
+		Active          bool   `json:"active"`
+		TokenType       string `json:"token_type,omitempty"`
+		Scope           string `json:"scope,omitempty"`
+		ClientID        string `json:"client_id,omitempty"`
+		Sub             string `json:"sub,omitempty"`
+		UserID          uint   `json:"user_id,omitempty"`
+		Email           string `json:"email,omitempty"`
+		RoleID          uint   `json:"role_id,omitempty"`
+		DivisionID      uint   `json:"division_id,omitempty"`
+		ScopeDivisionID *uint  `json:"scope_division_id,omitempty"`
+		Act             *Actor `json:"act,omitempty"`
+		Exp             int64  `json:"exp,omitempty"`
+		Iat             int64  `json:"iat,omitempty"`
+		Jti             string `json:"jti,omitempty"`
+	}
+
 	JWTClaims struct {
-		UserID     uint   `json:"user_id"`
-		Email      string `json:"email"`
-		RoleID     uint   `json:"role_id"`
-		DivisionID uint   `json:"division_id"`
+		UserID          uint     `json:"user_id"`
+		Email           string   `json:"email"`
+		RoleID          uint     `json:"role_id"`
+		DivisionID      uint     `json:"division_id"`
+		Permissions     []string `json:"permissions,omitempty"`
+		ScopeDivisionID *uint    `json:"scope_division_id,omitempty"`
+		Purpose         string   `json:"purpose,omitempty"`
+		Act             *Actor   `json:"act,omitempty"`
 		jwt.RegisteredClaims
 	}
-)
 
-func (r *RegisterEmployeeRequestBody) FillDefaults() {
-	var defaultRoleID uint = 1
-	if r.RoleID == nil {
-		r.RoleID = &defaultRoleID
+	// Actor is the employee acting on behalf of the subject of a token, as the
+	// "act" claim of RFC 8693. It is only set on impersonation tokens.
+	Actor struct {
+		Sub    string `json:"sub"`
+		UserID uint   `json:"user_id"`
+		Email  string `json:"email"`
 	}
-}
+)
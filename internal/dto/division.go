@@ -3,20 +3,61 @@ package dto
 import (
 	"time"
 
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	"gorm.io/gorm"
 )
 
 type (
+	DivisionSearchRequest struct {
+		pkgdto.SearchGetRequest
+		pkgdto.DeletedFilter
+	}
+	// DeleteDivisionRequest moves the employees of the division to ReassignTo
+	// before deleting it. Without ReassignTo a division still in use is kept.
+	DeleteDivisionRequest struct {
+		pkgdto.DeleteRequest
+		ReassignTo *uint `query:"reassign_to" validate:"omitempty"`
+	}
+	// CreateDivisionRequestBody has no head, as a new division has no
+	// employees yet.
 	CreateDivisionRequestBody struct {
-		Name *string `json:"name" validate:"required"`
+		Name         *string `json:"name" validate:"required"`
+		ParentID     *uint   `json:"parent_id" validate:"omitempty"`
+		Description  *string `json:"description" validate:"omitempty,max=1000"`
+		CostCenter   *string `json:"cost_center" validate:"omitempty,max=32"`
+		ContactEmail *string `json:"contact_email" validate:"omitempty,email|len=0"`
 	}
+	// UpdateDivisionRequestBody keeps the parent when ParentID is omitted, and
+	// makes the division a top-level one when ParentID is 0. HeadID 0 removes
+	// the head, and an empty string clears the other metadata.
 	UpdateDivisionRequestBody struct {
-		ID   *uint   `param:"id" validate:"required"`
-		Name *string `json:"name" validate:"required"`
+		ID           *uint   `param:"id" validate:"required"`
+		Name         *string `json:"name" validate:"required"`
+		ParentID     *uint   `json:"parent_id"`
+		HeadID       *uint   `json:"head_id"`
+		Description  *string `json:"description" validate:"omitempty,max=1000"`
+		CostCenter   *string `json:"cost_center" validate:"omitempty,max=32"`
+		ContactEmail *string `json:"contact_email" validate:"omitempty,email|len=0"`
 	}
 	DivisionResponse struct {
-		ID   uint   `json:"id"`
-		Name string `json:"name"`
+		ID           uint   `json:"id"`
+		Name         string `json:"name"`
+		ParentID     *uint  `json:"parent_id"`
+		HeadID       *uint  `json:"head_id"`
+		Description  string `json:"description"`
+		CostCenter   string `json:"cost_center"`
+		ContactEmail string `json:"contact_email"`
+	}
+	DivisionUsageResponse struct {
+		ID              uint  `json:"id"`
+		Employees       int64 `json:"employees"`
+		ScopedEmployees int64 `json:"scoped_employees"`
+		Subdivisions    int64 `json:"subdivisions"`
+		PendingChanges  int64 `json:"pending_changes"`
+	}
+	DivisionTreeResponse struct {
+		DivisionResponse
+		Children []*DivisionTreeResponse `json:"children"`
 	}
 	DivisionWithCUDResponse struct {
 		DivisionResponse
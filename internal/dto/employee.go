@@ -3,6 +3,8 @@ package dto
 import (
 	"time"
 
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	"gorm.io/gorm"
 )
 
@@ -11,9 +13,91 @@ type (
 		ID         *uint   `param:"id" validate:"required"`
 		Fullname   *string `json:"fullname" validate:"omitempty"`
 		Email      *string `json:"email" validate:"omitempty,email"`
-		Password   *string `json:"password" validate:"omitempty"`
 		RoleID     *uint   `json:"role_id" validate:"omitempty"`
 		DivisionID *uint   `json:"division_id" validate:"omitempty"`
+		// ManagerID 0 removes the manager of the employee.
+		ManagerID *uint `json:"manager_id" validate:"omitempty"`
+		// Profile fields other than the time zone and locale are cleared by an
+		// empty string.
+		JobTitle       *string `json:"job_title" validate:"omitempty,max=100"`
+		Phone          *string `json:"phone" validate:"omitempty,e164|len=0"`
+		EmployeeNumber *string `json:"employee_number" validate:"omitempty,max=32"`
+		Office         *string `json:"office" validate:"omitempty,max=100"`
+		Building       *string `json:"building" validate:"omitempty,max=100"`
+		Floor          *string `json:"floor" validate:"omitempty,max=16"`
+		TimeZone       *string `json:"time_zone" validate:"omitempty,timezone"`
+		Locale         *string `json:"locale" validate:"omitempty,locale"`
+	}
+	EmployeeSearchRequest struct {
+		pkgdto.SearchGetRequest
+		pkgdto.DeletedFilter
+		DivisionID *uint `query:"division_id" validate:"omitempty"`
+		// IncludeSubdivisions also lists the employees of every division
+		// nested below DivisionID.
+		IncludeSubdivisions bool   `query:"include_subdivisions"`
+		Status              string `query:"status" validate:"omitempty,oneof=invited active on_leave suspended terminated"`
+	}
+	// ChangeEmployeeStatusRequestBody moves an employee to Status. EffectiveAt
+	// defaults to now and may be in the past for changes recorded late.
+	ChangeEmployeeStatusRequestBody struct {
+		ID          uint       `param:"id" validate:"required"`
+		Status      string     `json:"status" validate:"required,oneof=invited active on_leave suspended terminated"`
+		EffectiveAt *time.Time `json:"effective_at"`
+		Reason      string     `json:"reason" validate:"omitempty,max=255"`
+	}
+	// SchedulePendingChangeRequestBody moves an employee to another division,
+	// role or status at EffectiveAt. At least one of them has to be given.
+	SchedulePendingChangeRequestBody struct {
+		ID          uint      `param:"id" validate:"required"`
+		DivisionID  *uint     `json:"division_id" validate:"omitempty"`
+		RoleID      *uint     `json:"role_id" validate:"omitempty"`
+		Status      *string   `json:"status" validate:"omitempty,oneof=invited active on_leave suspended terminated"`
+		EffectiveAt time.Time `json:"effective_at" validate:"required"`
+		Reason      string    `json:"reason" validate:"omitempty,max=255"`
+	}
+	PendingChangeSearchRequest struct {
+		ID uint `param:"id" validate:"required"`
+		// State defaults to pending.
+		State string `query:"state" validate:"omitempty,oneof=pending applied canceled failed"`
+	}
+	PendingChangeRequest struct {
+		ID       uint `param:"id" validate:"required"`
+		ChangeID uint `param:"change_id" validate:"required"`
+	}
+	// CreateEmployeeRequestBody creates an employee without a password, who
+	// sets one through the invitation sent to Email.
+	CreateEmployeeRequestBody struct {
+		Fullname   string `json:"fullname" validate:"required"`
+		Email      string `json:"email" validate:"required,email"`
+		RoleID     *uint  `json:"role_id" validate:"required"`
+		DivisionID *uint  `json:"division_id" validate:"required"`
+		ManagerID  *uint  `json:"manager_id" validate:"omitempty"`
+		EmployeeProfileRequestBody
+	}
+	EmployeeProfileRequestBody struct {
+		JobTitle       string  `json:"job_title" validate:"omitempty,max=100"`
+		Phone          string  `json:"phone" validate:"omitempty,e164"`
+		EmployeeNumber *string `json:"employee_number" validate:"omitempty,max=32"`
+		Office         string  `json:"office" validate:"omitempty,max=100"`
+		Building       string  `json:"building" validate:"omitempty,max=100"`
+		Floor          string  `json:"floor" validate:"omitempty,max=16"`
+		TimeZone       string  `json:"time_zone" validate:"omitempty,timezone"`
+		Locale         string  `json:"locale" validate:"omitempty,locale"`
+	}
+	AssignRoleRequestBody struct {
+		ID              *uint `param:"id" validate:"required"`
+		RoleID          *uint `json:"role_id" validate:"required"`
+		ScopeDivisionID *uint `json:"scope_division_id" validate:"omitempty"`
+	}
+	EmployeeReportsRequest struct {
+		ID uint `param:"id" validate:"required"`
+		// Depth limits how many levels below the manager are returned, all of
+		// them when omitted.
+		Depth int `query:"depth" validate:"omitempty,min=1,max=32"`
+	}
+	ChangePasswordRequestBody struct {
+		CurrentPassword string `json:"current_password" validate:"required"`
+		NewPassword     string `json:"new_password" validate:"required"`
 	}
 	EmployeeResponse struct {
 		ID       uint   `json:"id"`
@@ -22,7 +106,11 @@ type (
 	}
 	EmployeeWithJWTResponse struct {
 		EmployeeResponse
-		JWT string `json:"jwt"`
+		JWT                   string `json:"jwt,omitempty"`
+		RefreshToken          string `json:"refresh_token,omitempty"`
+		MFARequired           bool   `json:"mfa_required,omitempty"`
+		MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+		MFAToken              string `json:"mfa_token,omitempty"`
 	}
 	EmployeeWithCUDResponse struct {
 		EmployeeResponse
@@ -30,9 +118,82 @@ type (
 		UpdatedAt time.Time       `json:"updated_at"`
 		DeletedAt *gorm.DeletedAt `json:"deleted_at"`
 	}
+	EmployeeProfileResponse struct {
+		JobTitle       string  `json:"job_title"`
+		Phone          string  `json:"phone"`
+		EmployeeNumber *string `json:"employee_number"`
+		Office         string  `json:"office"`
+		Building       string  `json:"building"`
+		Floor          string  `json:"floor"`
+		TimeZone       string  `json:"time_zone"`
+		Locale         string  `json:"locale"`
+	}
 	EmployeeDetailResponse struct {
 		EmployeeResponse
-		Role     RoleResponse     `json:"role"`
-		Division DivisionResponse `json:"division"`
+		EmployeeProfileResponse
+		Role            RoleResponse     `json:"role"`
+		Division        DivisionResponse `json:"division"`
+		ScopeDivisionID *uint            `json:"scope_division_id,omitempty"`
+		ManagerID       *uint            `json:"manager_id"`
+		EmployeeStatusResponse
+	}
+	EmployeeStatusResponse struct {
+		Status            string     `json:"status"`
+		StatusEffectiveAt *time.Time `json:"status_effective_at"`
+	}
+	EmployeeStatusChangeResponse struct {
+		ID          uint      `json:"id"`
+		EmployeeID  uint      `json:"employee_id"`
+		FromStatus  string    `json:"from_status"`
+		ToStatus    string    `json:"to_status"`
+		EffectiveAt time.Time `json:"effective_at"`
+		Reason      string    `json:"reason"`
+		ChangedByID *uint     `json:"changed_by_id"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+	PendingChangeResponse struct {
+		ID            uint       `json:"id"`
+		EmployeeID    uint       `json:"employee_id"`
+		DivisionID    *uint      `json:"division_id"`
+		RoleID        *uint      `json:"role_id"`
+		Status        *string    `json:"status"`
+		EffectiveAt   time.Time  `json:"effective_at"`
+		State         string     `json:"state"`
+		Reason        string     `json:"reason"`
+		ScheduledByID *uint      `json:"scheduled_by_id"`
+		CanceledByID  *uint      `json:"canceled_by_id,omitempty"`
+		AppliedAt     *time.Time `json:"applied_at,omitempty"`
+		Error         string     `json:"error,omitempty"`
+		CreatedAt     time.Time  `json:"created_at"`
+	}
+	InvitedEmployeeResponse struct {
+		EmployeeDetailResponse
+		InvitationExpiresAt time.Time `json:"invitation_expires_at"`
+	}
+	EmployeeReportResponse struct {
+		EmployeeResponse
+		ManagerID *uint `json:"manager_id"`
+		Depth     int   `json:"depth"`
 	}
 )
+
+func (p *EmployeeProfileRequestBody) FillDefaults() {
+	if p.TimeZone == "" {
+		p.TimeZone = "UTC"
+	}
+	if p.Locale == "" {
+		p.Locale = "en"
+	}
+}
+
+// Apply copies the profile onto a new employee.
+func (p *EmployeeProfileRequestBody) Apply(employee *model.Employee) {
+	employee.JobTitle = p.JobTitle
+	employee.Phone = p.Phone
+	employee.EmployeeNumber = p.EmployeeNumber
+	employee.Office = p.Office
+	employee.Building = p.Building
+	employee.Floor = p.Floor
+	employee.TimeZone = p.TimeZone
+	employee.Locale = p.Locale
+}
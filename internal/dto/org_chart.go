@@ -0,0 +1,7 @@
+package dto
+
+type OrgChartRequest struct {
+	// Root limits the chart to the employee and everyone reporting to them.
+	Root   *uint  `query:"root" validate:"omitempty"`
+	Format string `query:"format" validate:"omitempty,oneof=json dot mermaid"`
+}
@@ -3,20 +3,45 @@ package dto
 import (
 	"time"
 
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	"gorm.io/gorm"
 )
 
 type (
+	RoleSearchRequest struct {
+		pkgdto.SearchGetRequest
+		pkgdto.DeletedFilter
+	}
+	// DeleteRoleRequest gives ReassignTo to the employees holding the role
+	// before deleting it. Without ReassignTo a role still in use is kept.
+	DeleteRoleRequest struct {
+		pkgdto.DeleteRequest
+		ReassignTo *uint `query:"reassign_to" validate:"omitempty"`
+	}
 	CreateRoleRequestBody struct {
-		Name *string `json:"name" validate:"required"`
+		Name     *string `json:"name" validate:"required"`
+		ParentID *uint   `json:"parent_id" validate:"omitempty"`
 	}
+	// UpdateRoleRequestBody keeps the parent when ParentID is omitted, and
+	// detaches the role from its parent when ParentID is 0.
 	UpdateRoleRequestBody struct {
-		ID   *uint   `param:"id" validate:"required"`
-		Name *string `json:"name" validate:"required"`
+		ID       *uint   `param:"id" validate:"required"`
+		Name     *string `json:"name" validate:"required"`
+		ParentID *uint   `json:"parent_id"`
+	}
+	UpdateRolePermissionsRequestBody struct {
+		ID          *uint    `param:"id" validate:"required"`
+		Permissions []string `json:"permissions" validate:"required"`
 	}
 	RoleResponse struct {
-		ID   uint   `json:"id"`
-		Name string `json:"name"`
+		ID          uint     `json:"id"`
+		Name        string   `json:"name"`
+		ParentID    *uint    `json:"parent_id"`
+		Permissions []string `json:"permissions"`
+	}
+	PermissionResponse struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
 	}
 	RoleWithCUDResponse struct {
 		RoleResponse
@@ -2,13 +2,23 @@ package factory
 
 import (
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/notifier"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
 )
 
 type Factory struct {
-	EmployeeRepository repository.Employee
-	DivisionRepository repository.Division
-	RoleRepository     repository.Role
+	EmployeeRepository           repository.Employee
+	DivisionRepository           repository.Division
+	RoleRepository               repository.Role
+	PermissionRepository         repository.Permission
+	RefreshTokenRepository       repository.RefreshToken
+	PasswordResetTokenRepository repository.PasswordResetToken
+	MFARepository                repository.MFA
+	APIKeyRepository             repository.APIKey
+	ImpersonationRepository      repository.Impersonation
+	InvitationRepository         repository.Invitation
+	PendingChangeRepository      repository.PendingChange
+	Notifier                     notifier.Notifier
 }
 
 func NewFactory() *Factory {
@@ -17,5 +27,14 @@ func NewFactory() *Factory {
 		repository.NewEmployeeRepository(db),
 		repository.NewDivisionRepository(db),
 		repository.NewRoleRepository(db),
+		repository.NewPermissionRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		repository.NewPasswordResetTokenRepository(db),
+		repository.NewMFARepository(db),
+		repository.NewAPIKeyRepository(db),
+		repository.NewImpersonationRepository(db),
+		repository.NewInvitationRepository(db),
+		repository.NewPendingChangeRepository(db),
+		notifier.NewNotifier(),
 	}
 }
@@ -1,25 +1,33 @@
 package http
 
 import (
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/apikey"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/auth"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/division"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/employee"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/orgchart"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/role"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
-	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
 )
 
 func NewHttp(e *echo.Echo, f *factory.Factory) {
-	e.Validator = &util.CustomValidator{Validator: validator.New()}
+	e.Validator = &util.CustomValidator{Validator: util.NewValidator()}
 
 	e.GET("/status", func(c echo.Context) error {
 		return c.JSON(200, map[string]string{"status": "OK"})
 	})
+	authHandler := auth.NewHandler(f)
+	e.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	v1 := e.Group("/api/v1")
+	v1.Use(middleware.APIKeyMiddleware(f.APIKeyRepository))
 	employee.NewHandler(f).Route(v1.Group("/employees"))
-	auth.NewHandler(f).Route(v1.Group("/auth"))
+	authHandler.Route(v1.Group("/auth"))
 	division.NewHandler(f).Route(v1.Group("/divisions"))
 	role.NewHandler(f).Route(v1.Group("/roles"))
+	apikey.NewHandler(f).Route(v1.Group("/api-keys"))
+	orgchart.NewHandler(f).Route(v1.Group("/org-chart"))
 }
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/repository"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/constant"
+	pkgutil "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
+	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+
+	apiKeyContextKey = "api_key"
+)
+
+// APIKeyMiddleware authenticates requests carrying an X-API-Key header and
+// stores the *model.APIKey in the context. Requests without the header are
+// passed through untouched, for JWTMiddleware to handle.
+func APIKeyMiddleware(apiKeyRepository repository.APIKey) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(APIKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			data, err := apiKeyRepository.FindByHash(ctx, pkgutil.HashToken(key))
+			if err != nil {
+				if err == constant.RECORD_NOT_FOUND {
+					return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, errors.New("invalid api key")).Send(c)
+				}
+				return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err).Send(c)
+			}
+			if data.RevokedAt != nil {
+				return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, errors.New("api key has been revoked")).Send(c)
+			}
+			if err := apiKeyRepository.TouchLastUsed(ctx, data, time.Minute); err != nil {
+				return res.ErrorBuilder(&res.ErrorConstant.InternalServerError, err).Send(c)
+			}
+
+			c.Set(apiKeyContextKey, data)
+			setPrincipal(c, auth.FromAPIKey(data))
+			return next(c)
+		}
+	}
+}
+
+func APIKeyFromContext(c echo.Context) (*model.APIKey, bool) {
+	key, ok := c.Get(apiKeyContextKey).(*model.APIKey)
+	return key, ok
+}
+
+// RequireScope rejects API keys that were not granted scope. Requests
+// authenticated with a JWT are not affected.
+func RequireScope(scope enum.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if p, ok := auth.PrincipalFrom(c.Request().Context()); ok && p.IsService() && !p.HasScope(scope) {
+				return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, errors.New("api key is missing scope "+string(scope))).Send(c)
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
+	"github.com/labstack/echo/v4"
+)
+
+// Principal returns the caller stored by APIKeyMiddleware or JWTMiddleware.
+// On routes without those middlewares the bearer token is parsed here, and the
+// result stored for the rest of the request.
+func Principal(c echo.Context) (*auth.Principal, error) {
+	if p, ok := auth.PrincipalFrom(c.Request().Context()); ok {
+		return p, nil
+	}
+	claims, err := util.ParseJWTToken(c.Request().Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	p := auth.FromClaims(claims)
+	setPrincipal(c, p)
+	return p, nil
+}
+
+// setPrincipal stores p in the request context, where services can read it
+// with auth.PrincipalFrom.
+func setPrincipal(c echo.Context, p *auth.Principal) {
+	c.SetRequest(c.Request().WithContext(auth.NewContext(c.Request().Context(), p)))
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
+	"github.com/labstack/echo/v4"
+)
+
+// DenyImpersonation rejects requests made with an impersonation token. Other
+// requests, including those without a valid access token, are left to the
+// handler.
+func DenyImpersonation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if p, err := Principal(c); err == nil {
+				if err := auth.NotImpersonating()(p); err != nil {
+					return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+				}
+			}
+			return next(c)
+		}
+	}
+}
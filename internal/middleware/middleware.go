@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
@@ -31,10 +33,23 @@ func LogMiddlewares(e *echo.Echo) {
 	}))
 }
 
-func JWTMiddleware(claims dto.JWTClaims, signingKey []byte) echo.MiddlewareFunc {
+// JWTMiddleware rejects requests without a valid, unrevoked bearer token and
+// stores the caller as an auth.Principal in the request context. Requests
+// already authenticated by APIKeyMiddleware are skipped.
+func JWTMiddleware() echo.MiddlewareFunc {
 	config := middleware.JWTConfig{
-		Claims:     &dto.JWTClaims{},
-		SigningKey: signingKey,
+		Skipper: func(c echo.Context) bool {
+			_, ok := APIKeyFromContext(c)
+			return ok
+		},
+		ParseTokenFunc: func(token string, c echo.Context) (interface{}, error) {
+			return util.ParseJWTTokenString(token)
+		},
+		SuccessHandler: func(c echo.Context) {
+			if claims, ok := c.Get("user").(*dto.JWTClaims); ok {
+				setPrincipal(c, auth.FromClaims(claims))
+			}
+		},
 	}
 	return middleware.JWTWithConfig(config)
 }
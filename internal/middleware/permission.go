@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	res "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util/response"
+	"github.com/labstack/echo/v4"
+)
+
+// RequirePermission rejects requests whose bearer token was not issued to a
+// role granted permission. API keys carry scopes rather than permissions, so
+// they are always rejected.
+func RequirePermission(permission enum.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p, err := Principal(c)
+			if err != nil {
+				return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+			}
+			if err := auth.Permission(permission)(p); err != nil {
+				return res.ErrorBuilder(&res.ErrorConstant.Unauthorized, err).Send(c)
+			}
+			return next(c)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"net/http/httptest"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
-	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
 )
 
@@ -14,7 +13,7 @@ type EchoMock struct {
 }
 
 func (em *EchoMock) RequestMock(method, path string, body io.Reader) (echo.Context, *httptest.ResponseRecorder) {
-	em.E.Validator = &util.CustomValidator{Validator: validator.New()}
+	em.E.Validator = &util.CustomValidator{Validator: util.NewValidator()}
 	req := httptest.NewRequest(method, path, body)
 	rec := httptest.NewRecorder()
 	c := em.E.NewContext(req, rec)
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/notifier"
+)
+
+type NotifierMock struct {
+	mu       sync.Mutex
+	Messages []notifier.Message
+}
+
+func (n *NotifierMock) Send(ctx context.Context, message notifier.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.Messages = append(n.Messages, message)
+	return nil
+}
+
+func (n *NotifierMock) Last() notifier.Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.Messages) == 0 {
+		return notifier.Message{}
+	}
+	return n.Messages[len(n.Messages)-1]
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey authenticates another service. Only a hash of the key is stored; the
+// prefix is kept in clear so admins can tell keys apart.
+type APIKey struct {
+	Name        string `json:"name" gorm:"varchar;not_null"`
+	Prefix      string `json:"prefix" gorm:"varchar;not_null"`
+	KeyHash     string `json:"-" gorm:"varchar;not_null;unique"`
+	Scopes      string `json:"scopes" gorm:"varchar;not_null"`
+	CreatedByID uint   `json:"created_by_id"`
+	CreatedBy   Employee
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	Common
+}
+
+// ScopeList splits the space separated scopes.
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,23 @@
 package model
 
+// Division is a node in the organisation, such as Finance, with departments
+// such as Accounting nested below it through ParentID. HeadID, when set, is an
+// employee of the division.
 type Division struct {
-	Name string `json:"name" gorm:"varchar;not_null;unique"`
+	Name         string    `json:"name" gorm:"varchar;not_null;unique"`
+	ParentID     *uint     `json:"parent_id"`
+	Parent       *Division `json:"-"`
+	HeadID       *uint     `json:"head_id" gorm:"index"`
+	Description  string    `json:"description" gorm:"type:text"`
+	CostCenter   string    `json:"cost_center" gorm:"size:32"`
+	ContactEmail string    `json:"contact_email" gorm:"size:255"`
 	Common
 }
+
+// DivisionUsage counts the records referring to a division.
+type DivisionUsage struct {
+	Employees       int64
+	ScopedEmployees int64
+	Subdivisions    int64
+	PendingChanges  int64
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type Employee struct {
 	Fullname   string `json:"fullname" gorm:"varchar;not_null"`
 	Email      string `json:"email" gorm:"varchar;not_null;unique"`
@@ -8,5 +10,32 @@ type Employee struct {
 	Role       Role
 	DivisionID uint `json:"division_id"`
 	Division   Division
+	// ScopeDivisionID limits the role of the employee to a single division,
+	// e.g. for division leads. Nil means the role applies to every division.
+	ScopeDivisionID *uint     `json:"scope_division_id"`
+	ScopeDivision   *Division `json:"-"`
+	ManagerID       *uint     `json:"manager_id" gorm:"index"`
+	Manager         *Employee `json:"-"`
+	// Profile fields shown by the booking UI. Rows created before they were
+	// added get the time zone and locale defaults.
+	JobTitle       string  `json:"job_title" gorm:"varchar"`
+	Phone          string  `json:"phone" gorm:"varchar"`
+	EmployeeNumber *string `json:"employee_number" gorm:"size:32;unique"`
+	Office         string  `json:"office" gorm:"varchar"`
+	Building       string  `json:"building" gorm:"varchar"`
+	Floor          string  `json:"floor" gorm:"varchar"`
+	TimeZone       string  `json:"time_zone" gorm:"size:64;not null;default:UTC"`
+	Locale         string  `json:"locale" gorm:"size:16;not null;default:en"`
+	// Status is one of enum.EmployeeStatuses and took effect at
+	// StatusEffectiveAt. Rows created before statuses were added are active.
+	Status            string     `json:"status" gorm:"size:16;not null;default:active;index"`
+	StatusEffectiveAt *time.Time `json:"status_effective_at"`
 	Common
 }
+
+// EmployeeReport is an employee found Depth levels below a manager, 1 being a
+// direct report.
+type EmployeeReport struct {
+	Employee
+	Depth int
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// EmployeeInvitation lets an employee created by an admin set their first
+// password. Only a hash of the token sent to the employee is stored.
+// InvitedByID is nil once the employee who sent the invitation is purged.
+type EmployeeInvitation struct {
+	EmployeeID  uint `json:"employee_id"`
+	Employee    Employee
+	InvitedByID *uint      `json:"invited_by_id"`
+	TokenHash   string     `json:"-" gorm:"varchar;not_null;unique"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	Common
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// EmployeeMFA holds the TOTP secret of an employee, encrypted at rest. It stays
+// disabled until the employee confirms enrollment with a valid code.
+type EmployeeMFA struct {
+	EmployeeID   uint `json:"employee_id" gorm:"not_null;unique"`
+	Employee     Employee
+	Secret       string     `json:"-" gorm:"varchar;not_null"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	LastUsedStep int64      `json:"-"`
+	Common
+}
+
+type MFARecoveryCode struct {
+	EmployeeID uint `json:"employee_id" gorm:"index"`
+	Employee   Employee
+	CodeHash   string     `json:"-" gorm:"varchar;not_null"`
+	UsedAt     *time.Time `json:"used_at"`
+	Common
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// EmployeePendingChange moves an employee to another division, role or status
+// once EffectiveAt is reached. Nil fields are left as they are. ScheduledByID
+// and CanceledByID are nil once the employee they refer to is purged.
+type EmployeePendingChange struct {
+	EmployeeID    uint `json:"employee_id" gorm:"index"`
+	Employee      Employee
+	DivisionID    *uint      `json:"division_id"`
+	RoleID        *uint      `json:"role_id"`
+	Status        *string    `json:"status" gorm:"size:16"`
+	EffectiveAt   time.Time  `json:"effective_at" gorm:"index:idx_pending_changes_due,priority:2"`
+	State         string     `json:"state" gorm:"size:16;not null;default:pending;index:idx_pending_changes_due,priority:1"`
+	Reason        string     `json:"reason" gorm:"size:255"`
+	ScheduledByID *uint      `json:"scheduled_by_id"`
+	CanceledByID  *uint      `json:"canceled_by_id"`
+	AppliedAt     *time.Time `json:"applied_at"`
+	Error         string     `json:"error" gorm:"size:255"`
+	Common
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// EmployeeStatusChange records an employee moving from one status to another.
+// EffectiveAt may be earlier than CreatedAt for changes recorded late.
+// ChangedByID is nil once the employee who made the change is purged.
+type EmployeeStatusChange struct {
+	EmployeeID  uint `json:"employee_id" gorm:"index"`
+	Employee    Employee
+	FromStatus  string    `json:"from_status" gorm:"size:16"`
+	ToStatus    string    `json:"to_status" gorm:"size:16"`
+	EffectiveAt time.Time `json:"effective_at"`
+	Reason      string    `json:"reason" gorm:"varchar"`
+	ChangedByID *uint     `json:"changed_by_id"`
+	Common
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// ImpersonationSession is the audit record of an employee signing in as
+// another one. TokenID is the jti of the impersonation token.
+type ImpersonationSession struct {
+	ActorID    uint `json:"actor_id" gorm:"index"`
+	Actor      Employee
+	EmployeeID uint `json:"employee_id" gorm:"index"`
+	Employee   Employee
+	TokenID    string     `json:"-" gorm:"varchar;not_null;unique"`
+	Reason     string     `json:"reason" gorm:"varchar"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+	Common
+}
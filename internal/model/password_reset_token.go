@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type PasswordResetToken struct {
+	EmployeeID uint `json:"employee_id"`
+	Employee   Employee
+	TokenHash  string     `json:"-" gorm:"varchar;not_null;unique"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	UsedAt     *time.Time `json:"used_at"`
+	Common
+}
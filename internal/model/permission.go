@@ -0,0 +1,13 @@
+package model
+
+type Permission struct {
+	Name        string `json:"name" gorm:"varchar;not_null;unique"`
+	Description string `json:"description" gorm:"varchar"`
+	Common
+}
+
+// RolePermission is the join table between Role and Permission.
+type RolePermission struct {
+	RoleID       uint `json:"role_id" gorm:"primaryKey"`
+	PermissionID uint `json:"permission_id" gorm:"primaryKey"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type RefreshToken struct {
+	EmployeeID uint `json:"employee_id"`
+	Employee   Employee
+	TokenHash  string     `json:"-" gorm:"varchar;not_null;unique"`
+	FamilyID   string     `json:"family_id" gorm:"varchar;not_null;index"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	UsedAt     *time.Time `json:"used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	Common
+}
@@ -1,6 +1,26 @@
 package model
 
+// Role grants its permissions, and through ParentID every permission of its
+// ancestors.
 type Role struct {
-	Name string `json:"name" gorm:"varchar;not_null;unique"`
+	Name        string       `json:"name" gorm:"varchar;not_null;unique"`
+	ParentID    *uint        `json:"parent_id"`
+	Parent      *Role        `json:"-"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions"`
 	Common
 }
+
+func (r *Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
+
+// RoleUsage counts the records referring to a role.
+type RoleUsage struct {
+	Employees      int64
+	ChildRoles     int64
+	PendingChanges int64
+}
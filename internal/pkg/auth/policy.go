@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrOutOfScope      = errors.New("resource is outside of the division scope")
+	ErrImpersonating   = errors.New("not allowed while impersonating")
+)
+
+// Policy decides whether a principal may perform an action, returning the
+// reason when it may not.
+type Policy func(p *Principal) error
+
+// Authorize checks the principal stored in ctx against every policy.
+func Authorize(ctx context.Context, policies ...Policy) error {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	for _, policy := range policies {
+		if err := policy(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Permission(permission enum.Permission) Policy {
+	return func(p *Principal) error {
+		if !p.HasPermission(permission) {
+			return fmt.Errorf("missing permission %s", permission)
+		}
+		return nil
+	}
+}
+
+// InDivision restricts division-scoped principals to resources of divisionID.
+func InDivision(divisionID uint) Policy {
+	return func(p *Principal) error {
+		if !p.InScope(divisionID) {
+			return ErrOutOfScope
+		}
+		return nil
+	}
+}
+
+// Unscoped rejects principals whose role was assigned for one division, for
+// actions whose effect would not be bound to that division.
+func Unscoped() Policy {
+	return func(p *Principal) error {
+		if p.ScopeDivisionID != nil {
+			return ErrOutOfScope
+		}
+		return nil
+	}
+}
+
+// Includes rejects principals lacking any of permissions, or whose division
+// scope is narrower than scopeDivisionID, so that acting as another employee
+// never grants more access than the principal already has.
+func Includes(permissions []string, scopeDivisionID *uint) Policy {
+	return func(p *Principal) error {
+		for _, name := range permissions {
+			if err := Permission(enum.Permission(name))(p); err != nil {
+				return err
+			}
+		}
+		if len(permissions) > 0 && p.ScopeDivisionID != nil &&
+			(scopeDivisionID == nil || *scopeDivisionID != *p.ScopeDivisionID) {
+			return ErrOutOfScope
+		}
+		return nil
+	}
+}
+
+// NotImpersonating rejects impersonation tokens, for actions an admin must not
+// take on behalf of someone else.
+func NotImpersonating() Policy {
+	return func(p *Principal) error {
+		if p.IsImpersonated() {
+			return ErrImpersonating
+		}
+		return nil
+	}
+}
+
+// SelfOr lets employees act on their own record, and everyone else only when
+// they satisfy policies.
+func SelfOr(employeeID uint, policies ...Policy) Policy {
+	return func(p *Principal) error {
+		if !p.IsService() && p.EmployeeID == employeeID {
+			return nil
+		}
+		for _, policy := range policies {
+			if err := policy(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
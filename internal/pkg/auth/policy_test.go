@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizeWithoutPrincipal(t *testing.T) {
+	err := Authorize(context.Background(), Permission(enum.RolesRead))
+	assert.Equal(t, ErrUnauthenticated, err)
+}
+
+func TestAuthorizePermission(t *testing.T) {
+	ctx := NewContext(context.Background(), FromClaims(&dto.JWTClaims{UserID: 2, Permissions: []string{string(enum.RolesRead)}}))
+
+	assert.NoError(t, Authorize(ctx, Permission(enum.RolesRead)))
+	assert.EqualError(t, Authorize(ctx, Permission(enum.RolesWrite)), "missing permission roles:write")
+}
+
+func TestAuthorizeSelfOr(t *testing.T) {
+	ctx := NewContext(context.Background(), FromClaims(&dto.JWTClaims{UserID: 2}))
+
+	assert.NoError(t, Authorize(ctx, SelfOr(2, Permission(enum.EmployeesWrite))))
+	assert.Error(t, Authorize(ctx, SelfOr(3, Permission(enum.EmployeesWrite))))
+}
+
+func TestAuthorizeSelfOrService(t *testing.T) {
+	ctx := NewContext(context.Background(), FromAPIKey(&model.APIKey{Scopes: "employees:read", Common: model.Common{ID: 1}}))
+
+	// a service has no employee id and is never the owner of a record
+	assert.Error(t, Authorize(ctx, SelfOr(0, Permission(enum.EmployeesWrite))))
+}
+
+func TestAuthorizeInDivision(t *testing.T) {
+	divisionID := uint(1)
+	scoped := NewContext(context.Background(), FromClaims(&dto.JWTClaims{UserID: 2, ScopeDivisionID: &divisionID}))
+	global := NewContext(context.Background(), FromClaims(&dto.JWTClaims{UserID: 1}))
+
+	assert.NoError(t, Authorize(scoped, InDivision(1)))
+	assert.Equal(t, ErrOutOfScope, Authorize(scoped, InDivision(2)))
+	assert.Equal(t, ErrOutOfScope, Authorize(scoped, Unscoped()))
+	assert.NoError(t, Authorize(global, InDivision(2), Unscoped()))
+}
+
+func TestAuthorizeNotImpersonating(t *testing.T) {
+	impersonated := NewContext(context.Background(), FromClaims(&dto.JWTClaims{UserID: 2, Act: &dto.Actor{Sub: "1", UserID: 1}}))
+	signedIn := NewContext(context.Background(), FromClaims(&dto.JWTClaims{UserID: 2}))
+
+	assert.Equal(t, ErrImpersonating, Authorize(impersonated, SelfOr(2), NotImpersonating()))
+	assert.NoError(t, Authorize(signedIn, SelfOr(2), NotImpersonating()))
+}
+
+func TestAuthorizeIncludes(t *testing.T) {
+	divisionID := uint(1)
+	otherDivisionID := uint(2)
+	scoped := NewContext(context.Background(), FromClaims(&dto.JWTClaims{UserID: 2, ScopeDivisionID: &divisionID, Permissions: []string{string(enum.RolesRead)}}))
+
+	assert.NoError(t, Authorize(scoped, Includes(nil, nil)))
+	assert.NoError(t, Authorize(scoped, Includes([]string{string(enum.RolesRead)}, &divisionID)))
+	assert.EqualError(t, Authorize(scoped, Includes([]string{string(enum.RolesWrite)}, &divisionID)), "missing permission roles:write")
+	assert.Equal(t, ErrOutOfScope, Authorize(scoped, Includes([]string{string(enum.RolesRead)}, nil)))
+	assert.Equal(t, ErrOutOfScope, Authorize(scoped, Includes([]string{string(enum.RolesRead)}, &otherDivisionID)))
+}
+
+func TestPrincipalHasScope(t *testing.T) {
+	p := FromAPIKey(&model.APIKey{Scopes: "employees:read divisions:read", Common: model.Common{ID: 1}})
+
+	assert.True(t, p.IsService())
+	assert.True(t, p.HasScope(enum.DivisionsRead))
+	assert.False(t, p.HasScope(enum.TokensIntrospect))
+	assert.False(t, p.HasPermission(enum.EmployeesWrite))
+}
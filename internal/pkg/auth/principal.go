@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+)
+
+// Principal is the authenticated caller of a request: an employee holding an
+// access token or a service holding an API key.
+type Principal struct {
+	EmployeeID      uint
+	Email           string
+	RoleID          uint
+	DivisionID      uint
+	ScopeDivisionID *uint
+	Permissions     []string
+
+	// ActorID is the employee signed in as EmployeeID, for impersonation tokens.
+	ActorID uint
+
+	// APIKeyID and Scopes are only set for services.
+	APIKeyID uint
+	Scopes   []string
+
+	// Claims is the verified access token, nil for services.
+	Claims *dto.JWTClaims
+}
+
+type contextKey struct{}
+
+func FromClaims(claims *dto.JWTClaims) *Principal {
+	p := &Principal{
+		EmployeeID:      claims.UserID,
+		Email:           claims.Email,
+		RoleID:          claims.RoleID,
+		DivisionID:      claims.DivisionID,
+		ScopeDivisionID: claims.ScopeDivisionID,
+		Permissions:     claims.Permissions,
+		Claims:          claims,
+	}
+	if claims.Act != nil {
+		p.ActorID = claims.Act.UserID
+	}
+	return p
+}
+
+func FromAPIKey(key *model.APIKey) *Principal {
+	return &Principal{
+		APIKeyID: key.ID,
+		Scopes:   key.ScopeList(),
+	}
+}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFrom returns the caller stored in ctx by the authentication
+// middlewares.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+func (p *Principal) IsService() bool {
+	return p.APIKeyID != 0
+}
+
+func (p *Principal) IsImpersonated() bool {
+	return p.ActorID != 0
+}
+
+// HasPermission reports whether the role of the employee was granted
+// permission when the access token was issued. Services have no permissions.
+func (p *Principal) HasPermission(permission enum.Permission) bool {
+	for _, name := range p.Permissions {
+		if name == string(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) HasScope(scope enum.Scope) bool {
+	for _, name := range p.Scopes {
+		if name == string(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// InScope reports whether the principal may act on resources of divisionID,
+// which is always the case unless its role was assigned for one division.
+func (p *Principal) InScope(divisionID uint) bool {
+	return p.ScopeDivisionID == nil || *p.ScopeDivisionID == divisionID
+}
@@ -0,0 +1,53 @@
+package enum
+
+// EmployeeStatus is the stage of employment of an employee. Only active
+// employees can sign in.
+type EmployeeStatus string
+
+const (
+	EmployeeInvited    EmployeeStatus = "invited"
+	EmployeeActive     EmployeeStatus = "active"
+	EmployeeOnLeave    EmployeeStatus = "on_leave"
+	EmployeeSuspended  EmployeeStatus = "suspended"
+	EmployeeTerminated EmployeeStatus = "terminated"
+)
+
+var EmployeeStatuses = []EmployeeStatus{
+	EmployeeInvited,
+	EmployeeActive,
+	EmployeeOnLeave,
+	EmployeeSuspended,
+	EmployeeTerminated,
+}
+
+// employeeStatusTransitions lists the statuses each status may change to.
+// Termination is final; a former employee comes back as a new employee.
+var employeeStatusTransitions = map[EmployeeStatus][]EmployeeStatus{
+	EmployeeInvited:   {EmployeeActive, EmployeeTerminated},
+	EmployeeActive:    {EmployeeOnLeave, EmployeeSuspended, EmployeeTerminated},
+	EmployeeOnLeave:   {EmployeeActive, EmployeeSuspended, EmployeeTerminated},
+	EmployeeSuspended: {EmployeeActive, EmployeeTerminated},
+}
+
+func (s EmployeeStatus) IsValid() bool {
+	for _, status := range EmployeeStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (s EmployeeStatus) CanTransitionTo(to EmployeeStatus) bool {
+	for _, status := range employeeStatusTransitions[s] {
+		if to == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CanSignIn reports whether employees of the status may sign in.
+func (s EmployeeStatus) CanSignIn() bool {
+	return s == EmployeeActive
+}
@@ -0,0 +1,13 @@
+package enum
+
+// PendingChangeState tracks a scheduled employee change. Changes start out
+// pending and end up applied, canceled or failed once they are due but can no
+// longer be made, e.g. because the target division was deleted.
+type PendingChangeState string
+
+const (
+	PendingChangePending  PendingChangeState = "pending"
+	PendingChangeApplied  PendingChangeState = "applied"
+	PendingChangeCanceled PendingChangeState = "canceled"
+	PendingChangeFailed   PendingChangeState = "failed"
+)
@@ -0,0 +1,71 @@
+package enum
+
+// Permission is an action a role may be granted. Permissions are checked by
+// name, so renaming one requires a migration of the role_permissions table.
+type Permission string
+
+const (
+	DivisionsWrite       Permission = "divisions:write"
+	RolesRead            Permission = "roles:read"
+	RolesWrite           Permission = "roles:write"
+	EmployeesWrite       Permission = "employees:write"
+	EmployeesDelete      Permission = "employees:delete"
+	EmployeesImpersonate Permission = "employees:impersonate"
+	SessionsManage       Permission = "sessions:manage"
+	APIKeysManage        Permission = "api_keys:manage"
+	RecordsPurge         Permission = "records:purge"
+)
+
+var Permissions = []Permission{
+	DivisionsWrite,
+	RolesRead,
+	RolesWrite,
+	EmployeesWrite,
+	EmployeesDelete,
+	EmployeesImpersonate,
+	SessionsManage,
+	APIKeysManage,
+	RecordsPurge,
+}
+
+func (p Permission) Description() string {
+	switch p {
+	case DivisionsWrite:
+		return "Create, update and delete divisions"
+	case RolesRead:
+		return "List roles and their permissions"
+	case RolesWrite:
+		return "Create, update and delete roles and grant permissions"
+	case EmployeesWrite:
+		return "Update any employee, including their role and division"
+	case EmployeesDelete:
+		return "Delete employees"
+	case EmployeesImpersonate:
+		return "Sign in as another employee, for support"
+	case SessionsManage:
+		return "Revoke sessions and unlock accounts of other employees"
+	case APIKeysManage:
+		return "Create and revoke API keys"
+	case RecordsPurge:
+		return "Permanently delete employees, divisions and roles"
+	}
+	return "Unknown"
+}
+
+func (p Permission) IsValid() bool {
+	for _, permission := range Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionNames returns the names of every known permission.
+func PermissionNames() []string {
+	names := make([]string, 0, len(Permissions))
+	for _, permission := range Permissions {
+		names = append(names, string(permission))
+	}
+	return names
+}
@@ -0,0 +1,22 @@
+package enum
+
+// Scope is a permission granted to an API key.
+type Scope string
+
+const (
+	EmployeesRead Scope = "employees:read"
+	DivisionsRead Scope = "divisions:read"
+	// TokensIntrospect allows a service to call the token introspection endpoint.
+	TokensIntrospect Scope = "tokens:introspect"
+)
+
+var Scopes = []Scope{EmployeesRead, DivisionsRead, TokensIntrospect}
+
+func (s Scope) IsValid() bool {
+	for _, scope := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package jwk
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePrivateKey(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writePublicKey(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pub.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func claims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+}
+
+func TestLoadHMAC(t *testing.T) {
+	asserts := assert.New(t)
+	keys, err := Load(Config{Method: "HS256", Secret: []byte("testsecret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := keys.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jwt.Parse(token, keys.Keyfunc)
+	asserts.NoError(err)
+	asserts.Empty(keys.JWKS().Keys)
+}
+
+func TestLoadRS256(t *testing.T) {
+	asserts := assert.New(t)
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := Load(Config{Method: "RS256", PrivateKeyFile: writePrivateKey(t, privateKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := keys.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := jwt.Parse(token, keys.Keyfunc)
+	if asserts.NoError(err) {
+		asserts.Equal(keys.SigningKey().ID, parsed.Header["kid"])
+	}
+
+	jwks := keys.JWKS()
+	if asserts.Len(jwks.Keys, 1) {
+		asserts.Equal("RSA", jwks.Keys[0].Kty)
+		asserts.Equal("RS256", jwks.Keys[0].Alg)
+		asserts.Equal("AQAB", jwks.Keys[0].E)
+		asserts.Equal(keys.SigningKey().ID, jwks.Keys[0].Kid)
+	}
+}
+
+func TestLoadES256WithRotation(t *testing.T) {
+	asserts := assert.New(t)
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldKeys, err := Load(Config{Method: "ES256", PrivateKeyFile: writePrivateKey(t, oldKey), KeyID: "2022-06"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := oldKeys.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := Load(Config{
+		Method:               "ES256",
+		PrivateKeyFile:       writePrivateKey(t, newKey),
+		VerificationKeyFiles: []string{"2022-06=" + writePublicKey(t, &oldKey.PublicKey)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = jwt.Parse(oldToken, keys.Keyfunc)
+	asserts.NoError(err)
+
+	jwks := keys.JWKS()
+	if asserts.Len(jwks.Keys, 2) {
+		asserts.Equal(keys.SigningKey().ID, jwks.Keys[0].Kid)
+		asserts.Equal("2022-06", jwks.Keys[1].Kid)
+		asserts.Equal("P-256", jwks.Keys[1].Crv)
+	}
+
+	// the rotated key can only verify
+	_, err = oldKeys.Sign(claims())
+	asserts.NoError(err)
+	_, err = keys.keys["2022-06"].Sign(claims())
+	asserts.Error(err)
+}
+
+func TestKeyfuncRejectsUnknownKeyAndAlgorithm(t *testing.T) {
+	asserts := assert.New(t)
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signing, err := NewPrivateKey("", nil, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := NewKeySet(signing)
+
+	token, err := NewHMACKey(signing.ID, []byte("testsecret")).Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jwt.Parse(token, keys.Keyfunc)
+	if asserts.Error(err) {
+		asserts.Contains(err.Error(), "invalid signing method")
+	}
+
+	token, err = NewHMACKey("unknown", []byte("testsecret")).Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jwt.Parse(token, keys.Keyfunc)
+	if asserts.Error(err) {
+		asserts.Contains(err.Error(), "unknown signing key")
+	}
+}
+
+func TestLoadMismatchedMethod(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load(Config{Method: "RS256", PrivateKeyFile: writePrivateKey(t, privateKey)})
+	assert.Error(t, err)
+}
+
+func TestThumbprintRFC7638(t *testing.T) {
+	// example key from RFC 7638 section 3.1
+	n := "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: 65537}
+	thumbprint, err := Thumbprint(key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+	}
+}
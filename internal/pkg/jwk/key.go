@@ -0,0 +1,169 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Key signs or verifies tokens with a single algorithm. Keys built from a
+// public key only can verify but not sign.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACKey returns a shared-secret key. It is never published in the JWKS.
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewPrivateKey returns a signing key for an RSA or ECDSA private key. An empty
+// id is replaced by the RFC 7638 thumbprint of the public key.
+func NewPrivateKey(id string, method jwt.SigningMethod, privateKey crypto.Signer) (*Key, error) {
+	expected, err := methodFor(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	if method == nil {
+		method = expected
+	}
+	if method.Alg() != expected.Alg() {
+		return nil, fmt.Errorf("signing method %s does not match a %s key", method.Alg(), expected.Alg())
+	}
+	if id == "" {
+		id, err = Thumbprint(privateKey.Public())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Key{
+		ID:        id,
+		Method:    method,
+		signKey:   privateKey,
+		verifyKey: privateKey.Public(),
+	}, nil
+}
+
+// NewPublicKey returns a verification-only key, used to keep accepting tokens
+// signed by a key that was rotated out.
+func NewPublicKey(id string, publicKey crypto.PublicKey) (*Key, error) {
+	method, err := methodFor(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		id, err = Thumbprint(publicKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Key{
+		ID:        id,
+		Method:    method,
+		verifyKey: publicKey,
+	}, nil
+}
+
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// IsPublic reports whether the key may be published, i.e. it is asymmetric.
+func (k *Key) IsPublic() bool {
+	_, isSecret := k.verifyKey.([]byte)
+	return !isSecret
+}
+
+func (k *Key) Sign(claims jwt.Claims) (string, error) {
+	if !k.CanSign() {
+		return "", fmt.Errorf("key %s cannot sign", k.ID)
+	}
+	token := jwt.NewWithClaims(k.Method, claims)
+	if k.ID != "" {
+		token.Header["kid"] = k.ID
+	}
+	return token.SignedString(k.signKey)
+}
+
+// JWK returns the public part of the key as a JSON Web Key.
+func (k *Key) JWK() (JWK, error) {
+	jwk := JWK{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.Method.Alg(),
+	}
+	switch publicKey := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encode(publicKey.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = publicKey.Curve.Params().Name
+		jwk.X = encode(publicKey.X.FillBytes(make([]byte, size)))
+		jwk.Y = encode(publicKey.Y.FillBytes(make([]byte, size)))
+	default:
+		return JWK{}, fmt.Errorf("key %s is not public", k.ID)
+	}
+	return jwk, nil
+}
+
+// Thumbprint computes the RFC 7638 SHA-256 thumbprint of an RSA or ECDSA public key.
+func Thumbprint(publicKey crypto.PublicKey) (string, error) {
+	key, err := NewPublicKey("-", publicKey)
+	if err != nil {
+		return "", err
+	}
+	jwk, err := key.JWK()
+	if err != nil {
+		return "", err
+	}
+
+	// members in lexicographic order, without whitespace
+	var canonical string
+	switch jwk.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, jwk.Crv, jwk.X, jwk.Y)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return encode(sum[:]), nil
+}
+
+func methodFor(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch publicKey.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported key type %T", publicKey)
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
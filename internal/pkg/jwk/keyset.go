@@ -0,0 +1,84 @@
+package jwk
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySet signs with a single active key and verifies with the active key plus
+// any number of previous keys, matched on the "kid" header.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	ids     []string
+}
+
+func NewKeySet(signing *Key, verification ...*Key) *KeySet {
+	s := &KeySet{
+		signing: signing,
+		keys:    make(map[string]*Key),
+	}
+	for _, key := range append([]*Key{signing}, verification...) {
+		if _, isExist := s.keys[key.ID]; isExist {
+			continue
+		}
+		s.keys[key.ID] = key
+		s.ids = append(s.ids, key.ID)
+	}
+	return s
+}
+
+func (s *KeySet) SigningKey() *Key {
+	return s.signing
+}
+
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	return s.signing.Sign(claims)
+}
+
+// Keyfunc resolves the verification key of a token for jwt.Parse. Tokens
+// without a "kid" header are checked against the signing key.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	key := s.signing
+	if kid, ok := token.Header["kid"].(string); ok {
+		if key, ok = s.keys[kid]; !ok {
+			return nil, fmt.Errorf("unknown signing key")
+		}
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("invalid signing method")
+	}
+	return key.verifyKey, nil
+}
+
+// JWKS lists the public keys of the set. Shared secrets are never included.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, id := range s.ids {
+		key := s.keys[id]
+		if !key.IsPublic() {
+			continue
+		}
+		if jwk, err := key.JWK(); err == nil {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
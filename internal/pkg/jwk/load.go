@@ -0,0 +1,104 @@
+package jwk
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type Config struct {
+	// Method is HS256, RS256 or ES256.
+	Method string
+	// Secret is the shared secret used by HS256.
+	Secret []byte
+	// PrivateKeyFile is the PEM encoded private key used by RS256 and ES256.
+	PrivateKeyFile string
+	// KeyID overrides the "kid" of the signing key, which defaults to its thumbprint.
+	KeyID string
+	// VerificationKeyFiles are PEM encoded keys, public or private, that are still
+	// accepted after a rotation. An entry may be prefixed with "<kid>=" to keep
+	// the key id it was published with.
+	VerificationKeyFiles []string
+}
+
+func Load(config Config) (*KeySet, error) {
+	method := jwt.GetSigningMethod(config.Method)
+	if method == nil {
+		return nil, fmt.Errorf("unknown signing method %q", config.Method)
+	}
+
+	var signing *Key
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unsupported signing method %q", config.Method)
+		}
+		signing = NewHMACKey(config.KeyID, config.Secret)
+	default:
+		if config.PrivateKeyFile == "" {
+			return nil, fmt.Errorf("a private key file is required for %s", method.Alg())
+		}
+		data, err := os.ReadFile(config.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err := ParsePrivateKeyPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		if signing, err = NewPrivateKey(config.KeyID, method, privateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	var verification []*Key
+	for _, entry := range config.VerificationKeyFiles {
+		id, path := "", entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			id, path = entry[:i], entry[i+1:]
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		key, err := NewPublicKey(id, publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		verification = append(verification, key)
+	}
+
+	return NewKeySet(signing, verification...), nil
+}
+
+// ParsePrivateKeyPEM parses an RSA or ECDSA private key in PKCS#1, SEC 1 or PKCS#8 form.
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPrivateKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported private key")
+}
+
+// ParsePublicKeyPEM parses an RSA or ECDSA public key, or derives it from a private key.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := ParsePrivateKeyPEM(data); err == nil {
+		return key.Public(), nil
+	}
+	return nil, fmt.Errorf("unsupported public key")
+}
@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// logNotifier writes messages to w instead of delivering them, for local development.
+type logNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLogNotifier(w io.Writer) *logNotifier {
+	return &logNotifier{w: w}
+}
+
+func (n *logNotifier) Send(ctx context.Context, message Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := fmt.Fprintf(
+		n.w,
+		"[%s] To: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339),
+		message.To,
+		message.Subject,
+		message.Body,
+	)
+	return err
+}
@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages such as password reset links to employees.
+type Notifier interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewNotifier builds the notifier selected by the NOTIFIER environment variable:
+// "smtp" sends emails, anything else writes messages to NOTIFIER_LOG_FILE or stdout.
+func NewNotifier() Notifier {
+	if util.Getenv("NOTIFIER", "log") == "smtp" {
+		return NewSMTPNotifier(
+			util.Getenv("SMTP_HOST", "localhost"),
+			util.Getenv("SMTP_PORT", "587"),
+			util.Getenv("SMTP_USERNAME", ""),
+			util.Getenv("SMTP_PASSWORD", ""),
+			util.Getenv("SMTP_FROM", "no-reply@localhost"),
+		)
+	}
+
+	fileName := util.Getenv("NOTIFIER_LOG_FILE", "")
+	if fileName == "" {
+		return NewLogNotifier(os.Stdout)
+	}
+	f, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		panic(fmt.Sprintf("error opening notifier log file: %v", err))
+	}
+	return NewLogNotifier(f)
+}
@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogNotifierSend(t *testing.T) {
+	var (
+		asserts = assert.New(t)
+		buf     = new(bytes.Buffer)
+		message = Message{
+			To:      "vincentlhubbard@superrito.com",
+			Subject: "Reset your password",
+			Body:    "http://localhost/reset-password?token=abc",
+		}
+	)
+	if asserts.NoError(NewLogNotifier(buf).Send(context.Background(), message)) {
+		asserts.Contains(buf.String(), "To: vincentlhubbard@superrito.com")
+		asserts.Contains(buf.String(), "Subject: Reset your password")
+		asserts.Contains(buf.String(), message.Body)
+	}
+}
+
+func TestSMTPNotifierBuildMessage(t *testing.T) {
+	var (
+		asserts = assert.New(t)
+		n       = NewSMTPNotifier("localhost", "25", "", "", "no-reply@superrito.com")
+		message = Message{
+			To:      "vincentlhubbard@superrito.com",
+			Subject: "Reset your password",
+			Body:    "line 1\nline 2",
+		}
+	)
+	msg := string(n.buildMessage(message))
+	asserts.Contains(msg, "From: no-reply@superrito.com\r\n")
+	asserts.Contains(msg, "To: vincentlhubbard@superrito.com\r\n")
+	asserts.Contains(msg, "Subject: Reset your password\r\n")
+	asserts.Contains(msg, "\r\n\r\nline 1\r\nline 2")
+}
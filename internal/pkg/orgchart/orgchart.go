@@ -0,0 +1,119 @@
+// Package orgchart builds the reporting structure of the organization as a
+// tree and renders it for documentation.
+package orgchart
+
+import (
+	"errors"
+	"sort"
+)
+
+var ErrRootNotFound = errors.New("root employee not found")
+
+type Division struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// Person is an employee as placed on the chart.
+type Person struct {
+	ID        uint
+	ManagerID *uint
+	Name      string
+	JobTitle  string
+	Role      string
+	Division  Division
+}
+
+type Node struct {
+	ID       uint     `json:"id"`
+	Name     string   `json:"name"`
+	JobTitle string   `json:"job_title,omitempty"`
+	Role     string   `json:"role"`
+	Division Division `json:"division"`
+	Reports  []*Node  `json:"reports"`
+}
+
+// Build arranges people by reporting line. Without root, every person whose
+// manager is not among people starts a tree. With root, the only tree returned
+// starts at that person. Siblings are sorted by name.
+func Build(people []Person, root *uint) ([]*Node, error) {
+	nodes := make(map[uint]*Node, len(people))
+	for _, p := range people {
+		nodes[p.ID] = &Node{
+			ID:       p.ID,
+			Name:     p.Name,
+			JobTitle: p.JobTitle,
+			Role:     p.Role,
+			Division: p.Division,
+			Reports:  []*Node{},
+		}
+	}
+
+	var roots []*Node
+	for _, p := range people {
+		node := nodes[p.ID]
+		var manager *Node
+		if p.ManagerID != nil {
+			manager = nodes[*p.ManagerID]
+		}
+		if manager != nil && manager != node {
+			manager.Reports = append(manager.Reports, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	for _, node := range nodes {
+		sortByName(node.Reports)
+	}
+	sortByName(roots)
+
+	if root == nil {
+		return roots, nil
+	}
+	node, ok := nodes[*root]
+	if !ok {
+		return nil, ErrRootNotFound
+	}
+	return []*Node{node}, nil
+}
+
+func sortByName(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Name != nodes[j].Name {
+			return nodes[i].Name < nodes[j].Name
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+}
+
+// walk visits every node of the trees once, parents before their reports. The
+// visited set keeps a cycle among people from looping forever.
+func walk(roots []*Node, visit func(node *Node)) {
+	visited := make(map[uint]bool)
+	var next func(nodes []*Node)
+	next = func(nodes []*Node) {
+		for _, node := range nodes {
+			if visited[node.ID] {
+				continue
+			}
+			visited[node.ID] = true
+			visit(node)
+			next(node.Reports)
+		}
+	}
+	next(roots)
+}
+
+// groupByDivision returns the nodes of the trees per division, divisions and
+// nodes in the order they are first visited.
+func groupByDivision(roots []*Node) ([]Division, map[uint][]*Node) {
+	var divisions []Division
+	members := make(map[uint][]*Node)
+	walk(roots, func(node *Node) {
+		if _, ok := members[node.Division.ID]; !ok {
+			divisions = append(divisions, node.Division)
+		}
+		members[node.Division.ID] = append(members[node.Division.ID], node)
+	})
+	return divisions, members
+}
@@ -0,0 +1,85 @@
+package orgchart
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	finance = Division{ID: 1, Name: "Finance"}
+	it      = Division{ID: 2, Name: "Information Technology"}
+	people  = []Person{
+		{ID: 3, ManagerID: uintPtr(2), Name: "Bettina", JobTitle: "Software Engineer", Role: "User", Division: it},
+		{ID: 1, Name: "Vincent", JobTitle: "Finance Manager", Role: "Admin", Division: finance},
+		{ID: 2, ManagerID: uintPtr(1), Name: "Devon", Role: "User", Division: finance},
+		{ID: 4, ManagerID: uintPtr(1), Name: "Alice \"Al\"", Role: "User", Division: finance},
+	}
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestBuild(t *testing.T) {
+	roots, err := Build(people, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, roots, 1) {
+		assert.Equal(t, uint(1), roots[0].ID)
+		if assert.Len(t, roots[0].Reports, 2) {
+			// sorted by name
+			assert.Equal(t, uint(4), roots[0].Reports[0].ID)
+			assert.Equal(t, uint(2), roots[0].Reports[1].ID)
+			assert.Equal(t, uint(3), roots[0].Reports[1].Reports[0].ID)
+		}
+	}
+}
+
+func TestBuildFromRoot(t *testing.T) {
+	roots, err := Build(people, uintPtr(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, roots, 1) {
+		assert.Equal(t, "Devon", roots[0].Name)
+		assert.Len(t, roots[0].Reports, 1)
+	}
+
+	_, err = Build(people, uintPtr(10))
+	assert.Equal(t, ErrRootNotFound, err)
+}
+
+func TestRenderDOT(t *testing.T) {
+	roots, _ := Build(people, nil)
+	var b strings.Builder
+	if err := RenderDOT(&b, roots); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	assert.True(t, strings.HasPrefix(out, "digraph OrgChart {\n"))
+	assert.Contains(t, out, "subgraph cluster_d1 {\n\t\tlabel=\"Finance\";")
+	assert.Contains(t, out, "subgraph cluster_d2 {")
+	assert.Contains(t, out, `e1 [label="Vincent\nFinance Manager"];`)
+	assert.Contains(t, out, `e4 [label="Alice \"Al\""];`)
+	assert.Contains(t, out, "e1 -> e2;")
+	assert.Contains(t, out, "e2 -> e3;")
+}
+
+func TestRenderMermaid(t *testing.T) {
+	roots, _ := Build(people, nil)
+	var b strings.Builder
+	if err := RenderMermaid(&b, roots); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	assert.True(t, strings.HasPrefix(out, "flowchart TD\n"))
+	assert.Contains(t, out, "subgraph d2[\"Information Technology\"]")
+	assert.Contains(t, out, `e3["Bettina<br/>Software Engineer"]`)
+	assert.Contains(t, out, `e4["Alice #quot;Al#quot;"]`)
+	assert.Contains(t, out, "e1 --> e2")
+}
@@ -0,0 +1,75 @@
+package orgchart
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RenderDOT writes the trees as a Graphviz graph with one cluster per
+// division.
+func RenderDOT(w io.Writer, roots []*Node) error {
+	var b strings.Builder
+	b.WriteString("digraph OrgChart {\n")
+	b.WriteString("\trankdir=TB;\n")
+	b.WriteString("\tnode [shape=box];\n")
+
+	divisions, members := groupByDivision(roots)
+	for _, division := range divisions {
+		fmt.Fprintf(&b, "\tsubgraph cluster_d%d {\n", division.ID)
+		fmt.Fprintf(&b, "\t\tlabel=%s;\n", dotQuote(division.Name))
+		for _, node := range members[division.ID] {
+			fmt.Fprintf(&b, "\t\te%d [label=%s];\n", node.ID, dotQuote(label(node, "\n")))
+		}
+		b.WriteString("\t}\n")
+	}
+	walk(roots, func(node *Node) {
+		for _, report := range node.Reports {
+			fmt.Fprintf(&b, "\te%d -> e%d;\n", node.ID, report.ID)
+		}
+	})
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderMermaid writes the trees as a Mermaid flowchart with one subgraph per
+// division.
+func RenderMermaid(w io.Writer, roots []*Node) error {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	divisions, members := groupByDivision(roots)
+	for _, division := range divisions {
+		fmt.Fprintf(&b, "\tsubgraph d%d[\"%s\"]\n", division.ID, mermaidEscape(division.Name))
+		for _, node := range members[division.ID] {
+			fmt.Fprintf(&b, "\t\te%d[\"%s\"]\n", node.ID, mermaidEscape(label(node, "<br/>")))
+		}
+		b.WriteString("\tend\n")
+	}
+	walk(roots, func(node *Node) {
+		for _, report := range node.Reports {
+			fmt.Fprintf(&b, "\te%d --> e%d\n", node.ID, report.ID)
+		}
+	})
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func label(node *Node, separator string) string {
+	if node.JobTitle == "" {
+		return node.Name
+	}
+	return node.Name + separator + node.JobTitle
+}
+
+func dotQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
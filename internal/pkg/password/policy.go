@@ -0,0 +1,183 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
+)
+
+// bcrypt ignores everything after the first 72 bytes of a password.
+const bcryptMaxLength = 72
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var commonPasswords = loadCommonPasswords(commonPasswordsFile)
+
+const (
+	RuleMinLength      = "min_length"
+	RuleMaxLength      = "max_length"
+	RuleUppercase      = "uppercase"
+	RuleLowercase      = "lowercase"
+	RuleDigit          = "digit"
+	RuleSymbol         = "symbol"
+	RulePersonalInfo   = "personal_info"
+	RuleCommonPassword = "common_password"
+)
+
+// Policy describes the requirements a new password must satisfy.
+type Policy struct {
+	MinLength            int
+	MaxLength            int
+	RequireUppercase     bool
+	RequireLowercase     bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	DisallowPersonalInfo bool
+	CheckCommon          bool
+}
+
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Violations lists every rule a password broke. It is returned as the details of
+// validation errors.
+type Violations []Violation
+
+func (v Violations) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, violation := range v {
+		messages = append(messages, violation.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// NewPolicy builds the policy configured through the environment.
+func NewPolicy() Policy {
+	return Policy{
+		MinLength:            util.GetenvInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:            util.GetenvInt("PASSWORD_MAX_LENGTH", bcryptMaxLength),
+		RequireUppercase:     util.GetenvBool("PASSWORD_REQUIRE_UPPERCASE", true),
+		RequireLowercase:     util.GetenvBool("PASSWORD_REQUIRE_LOWERCASE", true),
+		RequireDigit:         util.GetenvBool("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol:        util.GetenvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		DisallowPersonalInfo: util.GetenvBool("PASSWORD_DISALLOW_PERSONAL_INFO", true),
+		CheckCommon:          util.GetenvBool("PASSWORD_CHECK_COMMON", true),
+	}
+}
+
+// Validate checks password against every rule of the policy. personalInfo holds
+// values such as the employee's email and full name that must not appear in the
+// password. It returns nil when the password is acceptable.
+func (p Policy) Validate(password string, personalInfo ...string) Violations {
+	var violations Violations
+
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("password must be at least %d characters long", p.MinLength),
+		})
+	}
+	if maxLength := p.maxLength(); len(password) > maxLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("password must be at most %d bytes long", maxLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUppercase && !hasUpper {
+		violations = append(violations, Violation{Rule: RuleUppercase, Message: "password must contain an uppercase letter"})
+	}
+	if p.RequireLowercase && !hasLower {
+		violations = append(violations, Violation{Rule: RuleLowercase, Message: "password must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, Violation{Rule: RuleDigit, Message: "password must contain a digit"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, Violation{Rule: RuleSymbol, Message: "password must contain a symbol"})
+	}
+
+	if p.DisallowPersonalInfo && containsPersonalInfo(password, personalInfo) {
+		violations = append(violations, Violation{Rule: RulePersonalInfo, Message: "password must not contain your email or name"})
+	}
+	if p.CheckCommon && IsCommon(password) {
+		violations = append(violations, Violation{Rule: RuleCommonPassword, Message: "password is too common"})
+	}
+
+	return violations
+}
+
+func (p Policy) maxLength() int {
+	if p.MaxLength <= 0 || p.MaxLength > bcryptMaxLength {
+		return bcryptMaxLength
+	}
+	return p.MaxLength
+}
+
+// IsCommon reports whether password appears in the bundled list of common passwords.
+func IsCommon(password string) bool {
+	_, isExist := commonPasswords[strings.ToLower(password)]
+	return isExist
+}
+
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	lowered := strings.ToLower(password)
+	for _, info := range personalInfo {
+		for _, part := range personalInfoParts(info) {
+			if strings.Contains(lowered, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// personalInfoParts splits an email or a name into the fragments worth checking,
+// ignoring short ones like initials.
+func personalInfoParts(info string) []string {
+	info = strings.ToLower(strings.TrimSpace(info))
+	if at := strings.Index(info, "@"); at >= 0 {
+		info = info[:at]
+	}
+
+	var parts []string
+	for _, part := range strings.FieldsFunc(info, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(part)) >= 3 {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func loadCommonPasswords(file string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(file))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			passwords[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return passwords
+}
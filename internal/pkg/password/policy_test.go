@@ -0,0 +1,74 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPolicy = Policy{
+	MinLength:            8,
+	MaxLength:            72,
+	RequireUppercase:     true,
+	RequireLowercase:     true,
+	RequireDigit:         true,
+	RequireSymbol:        true,
+	DisallowPersonalInfo: true,
+	CheckCommon:          true,
+}
+
+func rules(violations Violations) []string {
+	var result []string
+	for _, violation := range violations {
+		result = append(result, violation.Rule)
+	}
+	return result
+}
+
+func TestPolicyValidateSuccess(t *testing.T) {
+	assert.Nil(t, testPolicy.Validate("123abcABC!", "vincentlhubbard@superrito.com", "Vincent L. Hubbard"))
+}
+
+func TestPolicyValidateTooShort(t *testing.T) {
+	violations := testPolicy.Validate("aB1!")
+	assert.Equal(t, []string{RuleMinLength}, rules(violations))
+	assert.Equal(t, "password must be at least 8 characters long", violations.Error())
+}
+
+func TestPolicyValidateTooLong(t *testing.T) {
+	violations := testPolicy.Validate("aB1!" + strings.Repeat("x", 72))
+	assert.Equal(t, []string{RuleMaxLength}, rules(violations))
+}
+
+func TestPolicyValidateMaxLengthCappedAtBcryptLimit(t *testing.T) {
+	policy := Policy{MaxLength: 100}
+	assert.Equal(t, []string{RuleMaxLength}, rules(policy.Validate(strings.Repeat("x", 73))))
+}
+
+func TestPolicyValidateCharacterClasses(t *testing.T) {
+	violations := testPolicy.Validate("          ")
+	assert.Equal(t, []string{RuleUppercase, RuleLowercase, RuleDigit}, rules(violations))
+
+	violations = testPolicy.Validate("abcdEFGH1234")
+	assert.Equal(t, []string{RuleSymbol}, rules(violations))
+}
+
+func TestPolicyValidatePersonalInfo(t *testing.T) {
+	violations := testPolicy.Validate("Hubbard123!", "vincentlhubbard@superrito.com", "Vincent L. Hubbard")
+	assert.Equal(t, []string{RulePersonalInfo}, rules(violations))
+
+	violations = testPolicy.Validate("xVincentLHubbard1!", "vincentlhubbard@superrito.com")
+	assert.Equal(t, []string{RulePersonalInfo}, rules(violations))
+}
+
+func TestPolicyValidateCommonPassword(t *testing.T) {
+	violations := Policy{CheckCommon: true}.Validate("Password123")
+	assert.Equal(t, []string{RuleCommonPassword}, rules(violations))
+}
+
+func TestIsCommon(t *testing.T) {
+	assert.True(t, IsCommon("qwerty"))
+	assert.True(t, IsCommon("QWERTY"))
+	assert.False(t, IsCommon("123abcABC!"))
+}
@@ -0,0 +1,89 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps track of access tokens that must be rejected before they expire.
+// Tokens are revoked one by one through their jti claim, or all at once for an
+// employee by recording the moment every previously issued token became invalid.
+type Store interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeEmployee(ctx context.Context, employeeID uint, revokedAt time.Time) error
+	IsEmployeeRevoked(ctx context.Context, employeeID uint, issuedAt time.Time) (bool, error)
+}
+
+// Precision is the precision of the issue time of access tokens, which has to
+// be set as jwt.TimePrecision. A token read back can be one unit short of its
+// issue time, as it travels as floating point seconds.
+const Precision = time.Microsecond
+
+type memoryStore struct {
+	mu          sync.RWMutex
+	maxTokenAge time.Duration
+	tokens      map[string]time.Time
+	employees   map[uint]time.Time
+}
+
+// NewMemoryStore returns a process local Store. maxTokenAge is the lifetime of
+// an access token, after which employee wide revocations no longer matter.
+func NewMemoryStore(maxTokenAge time.Duration) *memoryStore {
+	return &memoryStore{
+		maxTokenAge: maxTokenAge,
+		tokens:      make(map[string]time.Time),
+		employees:   make(map[uint]time.Time),
+	}
+}
+
+func (s *memoryStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+func (s *memoryStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	expiresAt, isExist := s.tokens[jti]
+	return isExist && time.Now().Before(expiresAt), nil
+}
+
+func (s *memoryStore) RevokeEmployee(ctx context.Context, employeeID uint, revokedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+	// one unit early, so that tokens issued after the revocation pass even when
+	// they read back a unit short; the ones issued less than two units before
+	// it pass as well
+	s.employees[employeeID] = revokedAt.Truncate(Precision).Add(-Precision)
+	return nil
+}
+
+func (s *memoryStore) IsEmployeeRevoked(ctx context.Context, employeeID uint, issuedAt time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revokedAt, isExist := s.employees[employeeID]
+	return isExist && issuedAt.Before(revokedAt), nil
+}
+
+func (s *memoryStore) prune(now time.Time) {
+	for jti, expiresAt := range s.tokens {
+		if now.After(expiresAt) {
+			delete(s.tokens, jti)
+		}
+	}
+	for employeeID, revokedAt := range s.employees {
+		if now.After(revokedAt.Add(s.maxTokenAge)) {
+			delete(s.employees, employeeID)
+		}
+	}
+}
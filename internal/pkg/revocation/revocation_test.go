@@ -0,0 +1,98 @@
+package revocation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreRevokeToken(t *testing.T) {
+	var (
+		asserts = assert.New(t)
+		ctx     = context.Background()
+		store   = NewMemoryStore(time.Hour)
+	)
+	if err := store.RevokeToken(ctx, "revoked", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	isRevoked, err := store.IsTokenRevoked(ctx, "revoked")
+	if asserts.NoError(err) {
+		asserts.True(isRevoked)
+	}
+	isRevoked, err = store.IsTokenRevoked(ctx, "active")
+	if asserts.NoError(err) {
+		asserts.False(isRevoked)
+	}
+}
+
+func TestMemoryStoreRevokeTokenExpired(t *testing.T) {
+	var (
+		asserts = assert.New(t)
+		ctx     = context.Background()
+		store   = NewMemoryStore(time.Hour)
+	)
+	if err := store.RevokeToken(ctx, "expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	isRevoked, err := store.IsTokenRevoked(ctx, "expired")
+	if asserts.NoError(err) {
+		asserts.False(isRevoked)
+	}
+}
+
+func TestMemoryStoreRevokeEmployee(t *testing.T) {
+	var (
+		asserts    = assert.New(t)
+		ctx        = context.Background()
+		store      = NewMemoryStore(time.Hour)
+		employeeID = uint(1)
+		now        = time.Now()
+	)
+	if err := store.RevokeEmployee(ctx, employeeID, now); err != nil {
+		t.Fatal(err)
+	}
+
+	isRevoked, err := store.IsEmployeeRevoked(ctx, employeeID, now.Add(-time.Minute))
+	if asserts.NoError(err) {
+		asserts.True(isRevoked)
+	}
+	isRevoked, err = store.IsEmployeeRevoked(ctx, employeeID, now.Add(time.Minute))
+	if asserts.NoError(err) {
+		asserts.False(isRevoked)
+	}
+	isRevoked, err = store.IsEmployeeRevoked(ctx, uint(2), now.Add(-time.Minute))
+	if asserts.NoError(err) {
+		asserts.False(isRevoked)
+	}
+}
+
+func TestMemoryStoreRevokeEmployeeSameSecond(t *testing.T) {
+	var (
+		asserts    = assert.New(t)
+		ctx        = context.Background()
+		store      = NewMemoryStore(time.Hour)
+		employeeID = uint(1)
+		now        = time.Now()
+	)
+	if err := store.RevokeEmployee(ctx, employeeID, now); err != nil {
+		t.Fatal(err)
+	}
+
+	isRevoked, err := store.IsEmployeeRevoked(ctx, employeeID, now.Add(-time.Millisecond))
+	if asserts.NoError(err) {
+		asserts.True(isRevoked)
+	}
+	isRevoked, err = store.IsEmployeeRevoked(ctx, employeeID, now)
+	if asserts.NoError(err) {
+		asserts.False(isRevoked)
+	}
+	// read back one unit short
+	isRevoked, err = store.IsEmployeeRevoked(ctx, employeeID, now.Truncate(Precision).Add(-Precision))
+	if asserts.NoError(err) {
+		asserts.False(isRevoked)
+	}
+}
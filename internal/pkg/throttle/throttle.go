@@ -0,0 +1,143 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
+)
+
+// Limiter tracks failed attempts per key, e.g. an account or an IP address, and
+// tells callers how long the key has to wait before trying again.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (retryAfter time.Duration, err error)
+	RegisterFailure(ctx context.Context, key string) (retryAfter time.Duration, err error)
+	Reset(ctx context.Context, key string) error
+}
+
+// Config controls how quickly a key gets throttled. After DelayThreshold
+// consecutive failures every new failure doubles the wait, starting at BaseDelay
+// and capped at MaxDelay. Reaching LockoutThreshold locks the key for
+// LockoutDuration. Failures older than LockoutDuration are forgotten.
+type Config struct {
+	DelayThreshold   int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+}
+
+// AccountConfig is the configuration applied to login attempts per account.
+func AccountConfig() Config {
+	return Config{
+		DelayThreshold:   util.GetenvInt("LOGIN_DELAY_THRESHOLD", 3),
+		BaseDelay:        util.GetenvDuration("LOGIN_BASE_DELAY", time.Second),
+		MaxDelay:         util.GetenvDuration("LOGIN_MAX_DELAY", time.Minute),
+		LockoutThreshold: util.GetenvInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+		LockoutDuration:  util.GetenvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+	}
+}
+
+// IPConfig is the configuration applied to login attempts per client IP. It is
+// more lenient than AccountConfig because many employees may share an address.
+func IPConfig() Config {
+	return Config{
+		DelayThreshold:   util.GetenvInt("LOGIN_IP_DELAY_THRESHOLD", 20),
+		BaseDelay:        util.GetenvDuration("LOGIN_BASE_DELAY", time.Second),
+		MaxDelay:         util.GetenvDuration("LOGIN_MAX_DELAY", time.Minute),
+		LockoutThreshold: util.GetenvInt("LOGIN_IP_LOCKOUT_THRESHOLD", 100),
+		LockoutDuration:  util.GetenvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+	}
+}
+
+type attempt struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+type memoryLimiter struct {
+	mu       sync.Mutex
+	config   Config
+	attempts map[string]*attempt
+	now      func() time.Time
+}
+
+func NewMemoryLimiter(config Config) *memoryLimiter {
+	return &memoryLimiter{
+		config:   config,
+		attempts: make(map[string]*attempt),
+		now:      time.Now,
+	}
+}
+
+func (l *memoryLimiter) Allow(ctx context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, isExist := l.attempts[key]
+	if !isExist {
+		return 0, nil
+	}
+	return l.retryAfter(a), nil
+}
+
+func (l *memoryLimiter) RegisterFailure(ctx context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	a, isExist := l.attempts[key]
+	if !isExist {
+		a = &attempt{}
+		l.attempts[key] = a
+	}
+	a.failures++
+	a.lastFailure = now
+
+	switch {
+	case l.config.LockoutThreshold > 0 && a.failures >= l.config.LockoutThreshold:
+		a.blockedUntil = now.Add(l.config.LockoutDuration)
+	case l.config.DelayThreshold > 0 && a.failures >= l.config.DelayThreshold:
+		a.blockedUntil = now.Add(l.delay(a.failures - l.config.DelayThreshold))
+	}
+
+	return l.retryAfter(a), nil
+}
+
+func (l *memoryLimiter) Reset(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, key)
+	return nil
+}
+
+func (l *memoryLimiter) delay(step int) time.Duration {
+	delay := l.config.BaseDelay
+	for i := 0; i < step; i++ {
+		delay *= 2
+		if l.config.MaxDelay > 0 && delay >= l.config.MaxDelay {
+			return l.config.MaxDelay
+		}
+	}
+	return delay
+}
+
+func (l *memoryLimiter) retryAfter(a *attempt) time.Duration {
+	if wait := a.blockedUntil.Sub(l.now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func (l *memoryLimiter) prune(now time.Time) {
+	for key, a := range l.attempts {
+		if now.Sub(a.lastFailure) > l.config.LockoutDuration && !now.Before(a.blockedUntil) {
+			delete(l.attempts, key)
+		}
+	}
+}
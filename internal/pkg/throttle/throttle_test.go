@@ -0,0 +1,96 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = Config{
+	DelayThreshold:   2,
+	BaseDelay:        time.Second,
+	MaxDelay:         3 * time.Second,
+	LockoutThreshold: 5,
+	LockoutDuration:  time.Minute,
+}
+
+func newTestLimiter(now *time.Time) *memoryLimiter {
+	limiter := NewMemoryLimiter(testConfig)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestMemoryLimiterProgressiveDelay(t *testing.T) {
+	var (
+		asserts = assert.New(t)
+		ctx     = context.Background()
+		now     = time.Now()
+		limiter = newTestLimiter(&now)
+	)
+
+	expected := []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second}
+	for _, want := range expected {
+		retryAfter, err := limiter.RegisterFailure(ctx, "account")
+		if asserts.NoError(err) {
+			asserts.Equal(want, retryAfter)
+		}
+	}
+
+	retryAfter, err := limiter.Allow(ctx, "account")
+	if asserts.NoError(err) {
+		asserts.Equal(3*time.Second, retryAfter)
+	}
+	retryAfter, err = limiter.Allow(ctx, "other")
+	if asserts.NoError(err) {
+		asserts.Equal(time.Duration(0), retryAfter)
+	}
+}
+
+func TestMemoryLimiterLockout(t *testing.T) {
+	var (
+		asserts = assert.New(t)
+		ctx     = context.Background()
+		now     = time.Now()
+		limiter = newTestLimiter(&now)
+	)
+	for i := 0; i < testConfig.LockoutThreshold; i++ {
+		if _, err := limiter.RegisterFailure(ctx, "account"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	retryAfter, err := limiter.Allow(ctx, "account")
+	if asserts.NoError(err) {
+		asserts.Equal(time.Minute, retryAfter)
+	}
+
+	now = now.Add(time.Minute + time.Second)
+	retryAfter, err = limiter.Allow(ctx, "account")
+	if asserts.NoError(err) {
+		asserts.Equal(time.Duration(0), retryAfter)
+	}
+}
+
+func TestMemoryLimiterReset(t *testing.T) {
+	var (
+		asserts = assert.New(t)
+		ctx     = context.Background()
+		now     = time.Now()
+		limiter = newTestLimiter(&now)
+	)
+	for i := 0; i < testConfig.LockoutThreshold; i++ {
+		if _, err := limiter.RegisterFailure(ctx, "account"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := limiter.Reset(ctx, "account"); err != nil {
+		t.Fatal(err)
+	}
+
+	retryAfter, err := limiter.Allow(ctx, "account")
+	if asserts.NoError(err) {
+		asserts.Equal(time.Duration(0), retryAfter)
+	}
+}
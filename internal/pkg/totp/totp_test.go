@@ -0,0 +1,63 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// base32 of the RFC 6238 SHA1 test key "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCodeRFCVectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range vectors {
+		code, err := GenerateCode(rfcSecret, time.Unix(unix, 0))
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, code, "time %d", unix)
+		}
+	}
+}
+
+func TestValidateWithSkew(t *testing.T) {
+	asserts := assert.New(t)
+	now := time.Unix(1234567890, 0)
+	code, err := GenerateCode(rfcSecret, now.Add(-Period*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, ok, err := Validate(rfcSecret, code, now)
+	if asserts.NoError(err) {
+		asserts.True(ok)
+		asserts.Equal(Step(now)-1, step)
+	}
+
+	_, ok, err = Validate(rfcSecret, code, now.Add(2*Period*time.Second))
+	if asserts.NoError(err) {
+		asserts.False(ok)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, secret, 32)
+
+	_, err = GenerateCode(secret, time.Now())
+	assert.NoError(t, err)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Meeting Room", "vincentlhubbard@superrito.com", rfcSecret)
+	assert.Equal(t, "otpauth://totp/Meeting%20Room:vincentlhubbard@superrito.com?algorithm=SHA1&digits=6&issuer=Meeting+Room&period=30&secret="+rfcSecret, uri)
+}
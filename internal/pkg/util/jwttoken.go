@@ -1,22 +1,105 @@
 package util
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/jwk"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/revocation"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
 	"github.com/golang-jwt/jwt/v4"
 )
 
 var (
-	JWT_SECRET         = []byte(util.Getenv("JWT_SECRET", "testsecret"))
-	JWT_EXP            = time.Duration(1) * time.Hour
-	JWT_SIGNING_METHOD = jwt.SigningMethodHS256
+	JWT_SECRET         []byte
+	JWT_EXP            time.Duration
+	JWT_SIGNING_METHOD string
+	REFRESH_TOKEN_EXP  time.Duration
+	MFA_TOKEN_EXP      time.Duration
+	// IMPERSONATION_TOKEN_EXP is the lifetime of the tokens issued to admins
+	// signing in as another employee. They cannot be refreshed.
+	IMPERSONATION_TOKEN_EXP time.Duration
+
+	// JWTKeys signs new tokens with the active key and verifies tokens signed by
+	// any key listed in JWT_VERIFICATION_KEY_FILES, which allows rotating keys
+	// without invalidating tokens that were already issued.
+	JWTKeys *jwk.KeySet
+
+	// RevocationStore is consulted every time a token is parsed. Replace it with a
+	// shared implementation when running more than one instance.
+	RevocationStore revocation.Store
+)
+
+// errTokenExpired keeps the message expired tokens were always rejected with.
+var errTokenExpired = errors.New("Token is expired")
+
+// Purposes of restricted tokens issued during login. Such tokens are only
+// accepted by the endpoint they were issued for, never as an access token.
+const (
+	MFA_PENDING_PURPOSE = "mfa_pending"
+	MFA_ENROLL_PURPOSE  = "mfa_enroll"
 )
 
+func init() {
+	// employee wide revocations have to tell apart tokens issued within the
+	// same second
+	jwt.TimePrecision = revocation.Precision
+	if err := LoadJWTConfig(); err != nil {
+		panic(err)
+	}
+}
+
+// LoadJWTConfig reads the token settings and keys from the environment. The
+// package loads them when it is initialised, before main has read .env, so
+// main loads them again once it has. It fails when RS256 or ES256 is chosen
+// without a usable private key.
+func LoadJWTConfig() error {
+	method := util.Getenv("JWT_SIGNING_METHOD", "HS256")
+	secret := []byte(util.Getenv("JWT_SECRET", "testsecret"))
+	keys, err := jwk.Load(jwk.Config{
+		Method:               method,
+		Secret:               secret,
+		PrivateKeyFile:       util.Getenv("JWT_PRIVATE_KEY_FILE", ""),
+		KeyID:                util.Getenv("JWT_KEY_ID", ""),
+		VerificationKeyFiles: splitList(util.Getenv("JWT_VERIFICATION_KEY_FILES", "")),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot load jwt keys: %w", err)
+	}
+
+	JWT_SECRET = secret
+	JWT_SIGNING_METHOD = method
+	JWT_EXP = util.GetenvDuration("JWT_EXP", time.Duration(15)*time.Minute)
+	REFRESH_TOKEN_EXP = util.GetenvDuration("REFRESH_TOKEN_EXP", time.Duration(7*24)*time.Hour)
+	MFA_TOKEN_EXP = util.GetenvDuration("MFA_TOKEN_EXP", time.Duration(5)*time.Minute)
+	IMPERSONATION_TOKEN_EXP = util.GetenvDuration("IMPERSONATION_TOKEN_EXP", time.Duration(15)*time.Minute)
+	JWTKeys = keys
+	RevocationStore = revocation.NewMemoryStore(maxDuration(JWT_EXP, IMPERSONATION_TOKEN_EXP))
+	return nil
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func getTokenString(authHeader string) (*string, error) {
 	var token string
 	if strings.Contains(authHeader, "Bearer") {
@@ -26,21 +109,41 @@ func getTokenString(authHeader string) (*string, error) {
 	return nil, fmt.Errorf("authorization not found")
 }
 
-func CreateJWTClaims(email string, userID, roleID, divisionID uint) dto.JWTClaims {
+func generateJTI() string {
+	jti, err := util.GenerateRandomToken(16)
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return jti
+}
+
+func CreateJWTClaims(email string, userID, roleID, divisionID uint, permissions ...string) dto.JWTClaims {
+	now := time.Now()
 	return dto.JWTClaims{
-		UserID:     userID,
-		Email:      email,
-		RoleID:     roleID,
-		DivisionID: divisionID,
+		UserID:      userID,
+		Email:       email,
+		RoleID:      roleID,
+		DivisionID:  divisionID,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(JWT_EXP)),
+			ID:        generateJTI(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(JWT_EXP)),
 		},
 	}
 }
 
 func CreateJWTToken(claims dto.JWTClaims) (string, error) {
-	token := jwt.NewWithClaims(JWT_SIGNING_METHOD, claims)
-	return token.SignedString([]byte(JWT_SECRET))
+	return JWTKeys.Sign(claims)
+}
+
+// CreateMFAToken signs a short-lived token that only proves the password step
+// of the login succeeded.
+func CreateMFAToken(email string, userID, roleID, divisionID uint, purpose string) (string, error) {
+	claims := CreateJWTClaims(email, userID, roleID, divisionID)
+	claims.Purpose = purpose
+	claims.ExpiresAt = jwt.NewNumericDate(claims.IssuedAt.Add(MFA_TOKEN_EXP))
+	return CreateJWTToken(claims)
 }
 
 func ParseJWTToken(authHeader string) (*dto.JWTClaims, error) {
@@ -48,28 +151,85 @@ func ParseJWTToken(authHeader string) (*dto.JWTClaims, error) {
 	if err != nil {
 		return nil, err
 	}
-	token, err := jwt.Parse(*tokenString, func(token *jwt.Token) (interface{}, error) {
-		if method, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || method != JWT_SIGNING_METHOD {
-			return nil, fmt.Errorf("invalid signing method")
-		}
-		return JWT_SECRET, nil
-	})
+	return ParseJWTTokenString(*tokenString)
+}
+
+// ParseJWTTokenString validates a raw access token, without the "Bearer" scheme,
+// and rejects it when it was revoked.
+func ParseJWTTokenString(tokenString string) (*dto.JWTClaims, error) {
+	claims, err := parseClaims(tokenString)
 	if err != nil {
 		return nil, err
 	}
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		claimsStr, err := json.Marshal(claims)
-		if err != nil {
-			return nil, fmt.Errorf("error when marshalling token")
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claims, nil
+}
+
+func ParseMFAToken(authHeader, purpose string) (*dto.JWTClaims, error) {
+	tokenString, err := getTokenString(authHeader)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMFATokenString(*tokenString, purpose)
+}
+
+// ParseMFATokenString validates a token created by CreateMFAToken for purpose.
+func ParseMFATokenString(tokenString, purpose string) (*dto.JWTClaims, error) {
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claims, nil
+}
+
+// parseClaims validates the token against dto.JWTClaims rather than
+// jwt.MapClaims, which only compares the issue time to the second.
+func parseClaims(tokenString string) (*dto.JWTClaims, error) {
+	var claims dto.JWTClaims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, JWTKeys.Keyfunc)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, errTokenExpired
 		}
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	if err := checkRevocation(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
 
-		var customClaims dto.JWTClaims
-		if err := json.Unmarshal(claimsStr, &customClaims); err != nil {
-			return nil, fmt.Errorf("error when unmarshalling token")
+func checkRevocation(claims *dto.JWTClaims) error {
+	ctx := context.Background()
+	if claims.ID != "" {
+		isRevoked, err := RevocationStore.IsTokenRevoked(ctx, claims.ID)
+		if err != nil {
+			return err
 		}
+		if isRevoked {
+			return fmt.Errorf("token has been revoked")
+		}
+	}
 
-		return &customClaims, nil
-	} else {
-		return nil, fmt.Errorf("invalid token")
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	isRevoked, err := RevocationStore.IsEmployeeRevoked(ctx, claims.UserID, issuedAt)
+	if err != nil {
+		return err
+	}
+	if isRevoked {
+		return fmt.Errorf("token has been revoked")
 	}
+	return nil
 }
@@ -1,9 +1,15 @@
 package util
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/jwk"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -52,3 +58,139 @@ func TestParseJWTTokenSuccess(t *testing.T) {
 	assert.Equal(t, divisionID, res.DivisionID)
 	assert.NotNil(t, res.ExpiresAt)
 }
+
+func TestParseJWTTokenRevokedToken(t *testing.T) {
+	claims := CreateJWTClaims("vincentlhubbard@superrito.com", 1, 1, 1)
+	tk, err := CreateJWTToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RevocationStore.RevokeToken(context.Background(), claims.ID, claims.ExpiresAt.Time); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseJWTToken(fmt.Sprintf("Bearer %s", tk))
+	if assert.Error(t, err) {
+		assert.Equal(t, "token has been revoked", err.Error())
+	}
+}
+
+func TestParseJWTTokenRevokedEmployee(t *testing.T) {
+	var employeeID uint = 99
+	claims := CreateJWTClaims("bettinameaster@superrito.com", employeeID, 2, 2)
+	revokedAt := claims.IssuedAt.Add(time.Millisecond)
+	tk, err := CreateJWTToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RevocationStore.RevokeEmployee(context.Background(), employeeID, revokedAt); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseJWTToken(fmt.Sprintf("Bearer %s", tk))
+	if assert.Error(t, err) {
+		assert.Equal(t, "token has been revoked", err.Error())
+	}
+}
+
+func TestParseJWTTokenIssuedRightAfterRevocation(t *testing.T) {
+	var employeeID uint = 98
+	if err := RevocationStore.RevokeEmployee(context.Background(), employeeID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	tk, err := CreateJWTToken(CreateJWTClaims("bettinameaster@superrito.com", employeeID, 2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseJWTToken(fmt.Sprintf("Bearer %s", tk))
+	assert.NoError(t, err)
+}
+
+func TestParseJWTTokenPermissions(t *testing.T) {
+	tk, err := CreateJWTToken(CreateJWTClaims("devoncthomas@superrito.com", 2, 2, 1, "roles:read"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseJWTToken(fmt.Sprintf("Bearer %s", tk))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"roles:read"}, claims.Permissions)
+	}
+}
+
+func TestParseJWTTokenActor(t *testing.T) {
+	claims := CreateJWTClaims("devoncthomas@superrito.com", 2, 2, 1)
+	claims.Act = &dto.Actor{Sub: "1", UserID: 1, Email: "vincentlhubbard@superrito.com"}
+	tk, err := CreateJWTToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseJWTToken(fmt.Sprintf("Bearer %s", tk))
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(2), parsed.UserID)
+		assert.Equal(t, claims.Act, parsed.Act)
+	}
+}
+
+func TestParseJWTTokenRejectsMFAToken(t *testing.T) {
+	tk, err := CreateMFAToken("vincentlhubbard@superrito.com", 1, 1, 1, MFA_PENDING_PURPOSE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseJWTToken(fmt.Sprintf("Bearer %s", tk))
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid token", err.Error())
+	}
+}
+
+func TestParseMFATokenSuccess(t *testing.T) {
+	tk, err := CreateMFAToken("vincentlhubbard@superrito.com", 1, 1, 1, MFA_PENDING_PURPOSE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseMFATokenString(tk, MFA_PENDING_PURPOSE)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(1), claims.UserID)
+		assert.Equal(t, MFA_PENDING_PURPOSE, claims.Purpose)
+	}
+
+	_, err = ParseMFATokenString(tk, MFA_ENROLL_PURPOSE)
+	assert.Error(t, err)
+}
+
+func TestParseJWTTokenRS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signing, err := jwk.NewPrivateKey("", nil, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultKeys := JWTKeys
+	JWTKeys = jwk.NewKeySet(signing)
+	defer func() { JWTKeys = defaultKeys }()
+
+	tk, err := CreateJWTToken(CreateJWTClaims("vincentlhubbard@superrito.com", 1, 1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ParseJWTToken(fmt.Sprintf("Bearer %s", tk))
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(1), res.UserID)
+	}
+
+	// tokens signed with the shared secret are no longer accepted
+	hs256, err := defaultKeys.Sign(CreateJWTClaims("vincentlhubbard@superrito.com", 1, 1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseJWTToken(fmt.Sprintf("Bearer %s", hs256))
+	assert.Error(t, err)
+}
+
+func TestLoadJWTConfigMissingPrivateKey(t *testing.T) {
+	t.Setenv("JWT_SIGNING_METHOD", "RS256")
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+
+	defaultKeys := JWTKeys
+	assert.Error(t, LoadJWTConfig())
+	assert.Same(t, defaultKeys, JWTKeys)
+}
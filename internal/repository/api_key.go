@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
+	"gorm.io/gorm"
+)
+
+type APIKey interface {
+	FindAll(ctx context.Context, payload *pkgdto.SearchGetRequest, p *pkgdto.Pagination) ([]model.APIKey, *pkgdto.PaginationInfo, error)
+	FindByID(ctx context.Context, id uint) (model.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	Save(ctx context.Context, key *model.APIKey) (*model.APIKey, error)
+	Revoke(ctx context.Context, key *model.APIKey) error
+	TouchLastUsed(ctx context.Context, key *model.APIKey, interval time.Duration) error
+}
+
+type apiKey struct {
+	Db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *apiKey {
+	return &apiKey{
+		db,
+	}
+}
+
+func (r *apiKey) FindAll(ctx context.Context, payload *pkgdto.SearchGetRequest, pagination *pkgdto.Pagination) ([]model.APIKey, *pkgdto.PaginationInfo, error) {
+	var keys []model.APIKey
+	var count int64
+
+	query := r.Db.WithContext(ctx).Model(&model.APIKey{})
+
+	if payload.Search != "" {
+		search := "%" + strings.ToLower(payload.Search) + "%"
+		query = query.Where("lower(name) LIKE ?", search)
+	}
+
+	countQuery := query
+	if err := countQuery.Count(&count).Error; err != nil {
+		return nil, nil, err
+	}
+
+	limit, offset := pkgdto.GetLimitOffset(pagination)
+
+	err := query.Limit(limit).Offset(offset).Find(&keys).Error
+
+	return keys, pkgdto.CheckInfoPagination(pagination, count), err
+}
+
+func (r *apiKey) FindByID(ctx context.Context, id uint) (model.APIKey, error) {
+	var data model.APIKey
+	if err := r.Db.WithContext(ctx).Model(&model.APIKey{}).Where("id = ?", id).First(&data).Error; err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+func (r *apiKey) FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	var data model.APIKey
+	if err := r.Db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (r *apiKey) Save(ctx context.Context, key *model.APIKey) (*model.APIKey, error) {
+	if err := r.Db.WithContext(ctx).Save(key).Error; err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *apiKey) Revoke(ctx context.Context, key *model.APIKey) error {
+	now := time.Now()
+	if err := r.Db.WithContext(ctx).Model(key).Where("revoked_at IS NULL").Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &now
+	}
+	return nil
+}
+
+// TouchLastUsed records the key usage at most once per interval, so busy
+// services do not write on every request.
+func (r *apiKey) TouchLastUsed(ctx context.Context, key *model.APIKey, interval time.Duration) error {
+	now := time.Now()
+	query := r.Db.WithContext(ctx).
+		Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", key.ID, now.Add(-interval)).
+		Update("last_used_at", now)
+	if err := query.Error; err != nil {
+		return err
+	}
+	if query.RowsAffected > 0 {
+		key.LastUsedAt = &now
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// ErrInUse is returned when purging a record that other records still refer
+// to, soft-deleted ones included.
+var ErrInUse = errors.New("record is still referenced")
+
+// ErrDuplicate is returned when saving a record would repeat a unique value.
+// The unique indexes cover soft-deleted records too, so a name or email stays
+// taken until its record is purged.
+var ErrDuplicate = errors.New("record already exists")
+
+// mysqlDuplicateEntry is the number of the MySQL error raised when a unique
+// index is violated.
+const mysqlDuplicateEntry = 1062
+
+// withDeleted widens the query to the soft-deleted records asked for by the
+// filter.
+func withDeleted(query *gorm.DB, filter pkgdto.DeletedFilter) *gorm.DB {
+	if filter.OnlyDeleted {
+		return query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if filter.IncludeDeleted {
+		return query.Unscoped()
+	}
+	return query
+}
+
+// inUse wraps ErrInUse with what still refers to the record when count is
+// positive.
+func inUse(count int64, what string) error {
+	if count > 0 {
+		return fmt.Errorf("%w by %s", ErrInUse, what)
+	}
+	return nil
+}
+
+// duplicate wraps err with ErrDuplicate when it was raised by a unique index.
+func duplicate(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return fmt.Errorf("%w: %s", ErrDuplicate, mysqlErr.Message)
+	}
+	return err
+}
@@ -3,22 +3,38 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	"gorm.io/gorm"
 )
 
 type Division interface {
-	FindAll(ctx context.Context, payload *pkgdto.SearchGetRequest, pagination *pkgdto.Pagination) ([]model.Division, *pkgdto.PaginationInfo, error)
+	FindAll(ctx context.Context, payload *dto.DivisionSearchRequest, pagination *pkgdto.Pagination) ([]model.Division, *pkgdto.PaginationInfo, error)
 	FindByID(ctx context.Context, id uint) (model.Division, error)
 	Save(ctx context.Context, division *dto.CreateDivisionRequestBody) (model.Division, error)
 	Edit(ctx context.Context, oldEmployee *model.Division, updateData *dto.UpdateDivisionRequestBody) (*model.Division, error)
 	Destroy(ctx context.Context, division *model.Division) (*model.Division, error)
 	ExistByName(ctx context.Context, name string) (bool, error)
+	FindChildren(ctx context.Context, id uint) ([]model.Division, error)
+	FindAncestors(ctx context.Context, id uint) ([]model.Division, error)
+	FindDescendants(ctx context.Context, id uint) ([]model.Division, error)
+	FindDeletedByID(ctx context.Context, id uint) (model.Division, error)
+	FindByIDUnscoped(ctx context.Context, id uint) (model.Division, error)
+	FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Division, error)
+	Restore(ctx context.Context, division *model.Division) (*model.Division, error)
+	Purge(ctx context.Context, division *model.Division) error
+	FindUsage(ctx context.Context, id uint) (model.DivisionUsage, error)
+	ReassignAndDestroy(ctx context.Context, division *model.Division, targetID uint) ([]uint, error)
 }
 
+// MaxDivisionDepth bounds the walks up and down the division tree, which also
+// stops them should a cycle ever make it into the table.
+const MaxDivisionDepth = 32
+
 type division struct {
 	Db *gorm.DB
 }
@@ -29,11 +45,11 @@ func NewDivisionRepository(db *gorm.DB) *division {
 	}
 }
 
-func (r *division) FindAll(ctx context.Context, payload *pkgdto.SearchGetRequest, pagination *pkgdto.Pagination) ([]model.Division, *pkgdto.PaginationInfo, error) {
+func (r *division) FindAll(ctx context.Context, payload *dto.DivisionSearchRequest, pagination *pkgdto.Pagination) ([]model.Division, *pkgdto.PaginationInfo, error) {
 	var divisions []model.Division
 	var count int64
 
-	query := r.Db.WithContext(ctx).Model(&model.Division{})
+	query := withDeleted(r.Db.WithContext(ctx).Model(&model.Division{}), payload.DeletedFilter)
 
 	if payload.Search != "" {
 		search := "%" + strings.ToLower(payload.Search) + "%"
@@ -62,10 +78,20 @@ func (r *division) FindByID(ctx context.Context, id uint) (model.Division, error
 
 func (r *division) Save(ctx context.Context, division *dto.CreateDivisionRequestBody) (model.Division, error) {
 	newDivision := model.Division{
-		Name: *division.Name,
+		Name:     *division.Name,
+		ParentID: division.ParentID,
+	}
+	if division.Description != nil {
+		newDivision.Description = *division.Description
+	}
+	if division.CostCenter != nil {
+		newDivision.CostCenter = *division.CostCenter
+	}
+	if division.ContactEmail != nil {
+		newDivision.ContactEmail = *division.ContactEmail
 	}
 	if err := r.Db.WithContext(ctx).Save(&newDivision).Error; err != nil {
-		return newDivision, err
+		return newDivision, duplicate(err)
 	}
 	return newDivision, nil
 }
@@ -74,9 +100,32 @@ func (r *division) Edit(ctx context.Context, oldDivision *model.Division, update
 	if updateData.Name != nil {
 		oldDivision.Name = *updateData.Name
 	}
+	if updateData.ParentID != nil {
+		if *updateData.ParentID == 0 {
+			oldDivision.ParentID = nil
+		} else {
+			oldDivision.ParentID = updateData.ParentID
+		}
+	}
+	if updateData.HeadID != nil {
+		if *updateData.HeadID == 0 {
+			oldDivision.HeadID = nil
+		} else {
+			oldDivision.HeadID = updateData.HeadID
+		}
+	}
+	if updateData.Description != nil {
+		oldDivision.Description = *updateData.Description
+	}
+	if updateData.CostCenter != nil {
+		oldDivision.CostCenter = *updateData.CostCenter
+	}
+	if updateData.ContactEmail != nil {
+		oldDivision.ContactEmail = *updateData.ContactEmail
+	}
 
 	if err := r.Db.WithContext(ctx).Save(oldDivision).Find(oldDivision).Error; err != nil {
-		return nil, err
+		return nil, duplicate(err)
 	}
 
 	return oldDivision, nil
@@ -102,3 +151,186 @@ func (r *division) ExistByName(ctx context.Context, name string) (bool, error) {
 	}
 	return isExist, nil
 }
+
+func (r *division) FindChildren(ctx context.Context, id uint) ([]model.Division, error) {
+	var children []model.Division
+	if err := r.Db.WithContext(ctx).Where("parent_id = ?", id).Order("name").Find(&children).Error; err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+// FindAncestors returns the parent of the division, the parent of that parent
+// and so on, the nearest first.
+func (r *division) FindAncestors(ctx context.Context, id uint) ([]model.Division, error) {
+	var ancestors []model.Division
+	err := r.Db.WithContext(ctx).Raw(`
+		WITH RECURSIVE chain (id, parent_id, depth) AS (
+			SELECT id, parent_id, 0 FROM divisions WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT d.id, d.parent_id, chain.depth + 1
+			FROM divisions d JOIN chain ON d.id = chain.parent_id
+			WHERE d.deleted_at IS NULL AND chain.depth < ?
+		)
+		SELECT divisions.* FROM divisions JOIN chain ON chain.id = divisions.id
+		WHERE chain.depth > 0
+		ORDER BY chain.depth`, id, MaxDivisionDepth).
+		Scan(&ancestors).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return ancestors, nil
+}
+
+// FindDescendants returns every division below the division, level by level.
+func (r *division) FindDescendants(ctx context.Context, id uint) ([]model.Division, error) {
+	var descendants []model.Division
+	err := r.Db.WithContext(ctx).Raw(`
+		WITH RECURSIVE subtree (id, depth) AS (
+			SELECT id, 1 FROM divisions WHERE parent_id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT d.id, subtree.depth + 1
+			FROM divisions d JOIN subtree ON d.parent_id = subtree.id
+			WHERE d.deleted_at IS NULL AND subtree.depth < ?
+		)
+		SELECT divisions.* FROM divisions JOIN subtree ON subtree.id = divisions.id
+		ORDER BY subtree.depth, divisions.name`, id, MaxDivisionDepth).
+		Scan(&descendants).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return descendants, nil
+}
+
+func (r *division) FindDeletedByID(ctx context.Context, id uint) (model.Division, error) {
+	var data model.Division
+	err := r.Db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&data).Error
+	return data, err
+}
+
+// FindByIDUnscoped finds the division whether it is soft-deleted or not.
+func (r *division) FindByIDUnscoped(ctx context.Context, id uint) (model.Division, error) {
+	var data model.Division
+	err := r.Db.WithContext(ctx).Unscoped().Where("id = ?", id).First(&data).Error
+	return data, err
+}
+
+// FindDeletedBefore returns at most limit divisions soft-deleted before the
+// given time, the longest deleted first. Divisions Purge would keep are left
+// out, so that they cannot fill every batch.
+func (r *division) FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Division, error) {
+	var divisions []model.Division
+	err := r.Db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM employees WHERE employees.division_id = divisions.id OR employees.scope_division_id = divisions.id)").
+		Where("NOT EXISTS (SELECT 1 FROM divisions children WHERE children.parent_id = divisions.id)").
+		Order("deleted_at").
+		Limit(limit).
+		Find(&divisions).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return divisions, nil
+}
+
+func (r *division) Restore(ctx context.Context, division *model.Division) (*model.Division, error) {
+	if err := r.Db.WithContext(ctx).Unscoped().Model(division).Update("deleted_at", nil).Error; err != nil {
+		return nil, duplicate(err)
+	}
+	division.DeletedAt = nil
+	return division, nil
+}
+
+// Purge removes the division for good, unless employees or subdivisions, even
+// soft-deleted ones, still refer to it.
+func (r *division) Purge(ctx context.Context, division *model.Division) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var employees, children int64
+		err := tx.Unscoped().
+			Model(&model.Employee{}).
+			Where("division_id = ? OR scope_division_id = ?", division.ID, division.ID).
+			Count(&employees).
+			Error
+		if err != nil {
+			return err
+		}
+		if err := inUse(employees, "employees"); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Division{}).Where("parent_id = ?", division.ID).Count(&children).Error; err != nil {
+			return err
+		}
+		if err := inUse(children, "subdivisions"); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(division).Error
+	})
+}
+
+// FindUsage counts the employees, subdivisions and pending changes referring
+// to the division. Soft-deleted records are not counted.
+func (r *division) FindUsage(ctx context.Context, id uint) (model.DivisionUsage, error) {
+	var usage model.DivisionUsage
+	db := r.Db.WithContext(ctx)
+	if err := db.Model(&model.Employee{}).Where("division_id = ?", id).Count(&usage.Employees).Error; err != nil {
+		return usage, err
+	}
+	if err := db.Model(&model.Employee{}).Where("scope_division_id = ?", id).Count(&usage.ScopedEmployees).Error; err != nil {
+		return usage, err
+	}
+	if err := db.Model(&model.Division{}).Where("parent_id = ?", id).Count(&usage.Subdivisions).Error; err != nil {
+		return usage, err
+	}
+	err := db.Model(&model.EmployeePendingChange{}).
+		Where("division_id = ? AND state = ?", id, enum.PendingChangePending).
+		Count(&usage.PendingChanges).
+		Error
+	return usage, err
+}
+
+// ReassignAndDestroy moves the employees of the division, soft-deleted ones
+// included, the employees whose role is scoped to it, its subdivisions and the
+// pending changes into it to the target division, then deletes the division,
+// all in one transaction. It returns the IDs of the employees moved.
+func (r *division) ReassignAndDestroy(ctx context.Context, division *model.Division, targetID uint) ([]uint, error) {
+	var moved []uint
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Model(&model.Employee{}).
+			Where("division_id = ? OR scope_division_id = ?", division.ID, division.ID).
+			Pluck("id", &moved).
+			Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Employee{}).Where("division_id = ?", division.ID).Update("division_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Employee{}).Where("scope_division_id = ?", division.ID).Update("scope_division_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Division{}).Where("parent_id = ?", division.ID).Update("parent_id", targetID).Error; err != nil {
+			return err
+		}
+		err = tx.Model(&model.EmployeePendingChange{}).
+			Where("division_id = ? AND state = ?", division.ID, enum.PendingChangePending).
+			Update("division_id", targetID).
+			Error
+		if err != nil {
+			return err
+		}
+		// the head moved along with the other employees
+		if err := tx.Model(division).Update("head_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(division).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
@@ -3,25 +3,43 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
-	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/util"
 	"gorm.io/gorm"
 )
 
 type Employee interface {
-	FindAll(ctx context.Context, payload *pkgdto.SearchGetRequest, p *pkgdto.Pagination) ([]model.Employee, *pkgdto.PaginationInfo, error)
+	FindAll(ctx context.Context, payload *dto.EmployeeSearchRequest, p *pkgdto.Pagination, divisionIDs []uint) ([]model.Employee, *pkgdto.PaginationInfo, error)
 	FindByID(ctx context.Context, id uint, usePreload bool) (model.Employee, error)
 	FindByEmail(ctx context.Context, email *string) (*model.Employee, error)
 	ExistByEmail(ctx context.Context, email *string) (bool, error)
 	ExistByID(ctx context.Context, id uint) (bool, error)
-	Save(ctx context.Context, employee *dto.RegisterEmployeeRequestBody) (model.Employee, error)
+	ExistByEmployeeNumber(ctx context.Context, employeeNumber string) (bool, error)
+	Save(ctx context.Context, employee *model.Employee) (*model.Employee, error)
 	Edit(ctx context.Context, oldEmployee *model.Employee, updateData *dto.UpdateEmployeeRequestBody) (*model.Employee, error)
+	EditPassword(ctx context.Context, employee *model.Employee, hashedPassword string) (*model.Employee, error)
+	EditRoleAssignment(ctx context.Context, employee *model.Employee, roleID uint, scopeDivisionID *uint) (*model.Employee, error)
 	Destroy(ctx context.Context, employee *model.Employee) (*model.Employee, error)
+	FindAllWithRelations(ctx context.Context, divisionID *uint) ([]model.Employee, error)
+	FindManagerChain(ctx context.Context, id uint) ([]model.Employee, error)
+	FindDirectReports(ctx context.Context, id uint) ([]model.Employee, error)
+	FindReports(ctx context.Context, id uint, depth int) ([]model.EmployeeReport, error)
+	EditStatus(ctx context.Context, employee *model.Employee, change *model.EmployeeStatusChange) (*model.Employee, error)
+	FindStatusChanges(ctx context.Context, id uint) ([]model.EmployeeStatusChange, error)
+	FindDeletedByID(ctx context.Context, id uint) (model.Employee, error)
+	FindByIDUnscoped(ctx context.Context, id uint) (model.Employee, error)
+	FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Employee, error)
+	Restore(ctx context.Context, employee *model.Employee) (*model.Employee, error)
+	Purge(ctx context.Context, employee *model.Employee) error
 }
 
+// MaxReportingDepth bounds the walks along reporting lines, which also stops
+// them should a cycle ever make it into the table.
+const MaxReportingDepth = 32
+
 type employee struct {
 	Db *gorm.DB
 }
@@ -32,11 +50,20 @@ func NewEmployeeRepository(db *gorm.DB) *employee {
 	}
 }
 
-func (r *employee) FindAll(ctx context.Context, payload *pkgdto.SearchGetRequest, pagination *pkgdto.Pagination) ([]model.Employee, *pkgdto.PaginationInfo, error) {
+// FindAll lists employees, restricted to the divisions in divisionIDs when it
+// is not nil. An empty, non-nil divisionIDs matches no employee.
+func (r *employee) FindAll(ctx context.Context, payload *dto.EmployeeSearchRequest, pagination *pkgdto.Pagination, divisionIDs []uint) ([]model.Employee, *pkgdto.PaginationInfo, error) {
 	var users []model.Employee
 	var count int64
 
-	query := r.Db.WithContext(ctx).Model(&model.Employee{})
+	query := withDeleted(r.Db.WithContext(ctx).Model(&model.Employee{}), payload.DeletedFilter)
+
+	if divisionIDs != nil {
+		query = query.Where("division_id IN ?", divisionIDs)
+	}
+	if payload.Status != "" {
+		query = query.Where("status = ?", payload.Status)
+	}
 
 	if payload.Search != "" {
 		search := "%" + strings.ToLower(payload.Search) + "%"
@@ -102,44 +129,99 @@ func (r *employee) ExistByID(ctx context.Context, id uint) (bool, error) {
 	return isExist, nil
 }
 
-func (r *employee) Save(ctx context.Context, employee *dto.RegisterEmployeeRequestBody) (model.Employee, error) {
-	newEmployee := model.Employee{
-		Fullname:   employee.Fullname,
-		Email:      employee.Email,
-		Password:   employee.Password,
-		RoleID:     *employee.RoleID,
-		DivisionID: *employee.DivisionID,
+func (r *employee) ExistByEmployeeNumber(ctx context.Context, employeeNumber string) (bool, error) {
+	var (
+		count   int64
+		isExist bool
+	)
+	if err := r.Db.WithContext(ctx).Model(&model.Employee{}).Where("employee_number = ?", employeeNumber).Count(&count).Error; err != nil {
+		return isExist, err
 	}
-	if err := r.Db.WithContext(ctx).Save(&newEmployee).Error; err != nil {
-		return newEmployee, err
+	if count > 0 {
+		isExist = true
+	}
+	return isExist, nil
+}
+
+// Save creates the employee. An invited employee has no password yet, and
+// cannot sign in until they accept the invitation.
+func (r *employee) Save(ctx context.Context, employee *model.Employee) (*model.Employee, error) {
+	if err := r.Db.WithContext(ctx).Save(employee).Error; err != nil {
+		return nil, duplicate(err)
 	}
-	return newEmployee, nil
+	return employee, nil
 }
 
+// Edit saves the changes to the employee. An employee moved to another division
+// stops heading the division they leave.
 func (r *employee) Edit(ctx context.Context, oldEmployee *model.Employee, updateData *dto.UpdateEmployeeRequestBody) (*model.Employee, error) {
+	transferred := updateData.DivisionID != nil && *updateData.DivisionID != oldEmployee.DivisionID
 	if updateData.Fullname != nil {
 		oldEmployee.Fullname = *updateData.Fullname
 	}
 	if updateData.Email != nil {
 		oldEmployee.Email = *updateData.Email
 	}
-	if updateData.Password != nil {
-		hashedPassword, err := util.HashPassword(*updateData.Password)
-		if err != nil {
-			return nil, err
-		}
-		oldEmployee.Password = hashedPassword
-	}
 	if updateData.DivisionID != nil {
 		oldEmployee.DivisionID = *updateData.DivisionID
 	}
 	if updateData.RoleID != nil {
 		oldEmployee.RoleID = *updateData.RoleID
 	}
+	if updateData.ManagerID != nil {
+		if *updateData.ManagerID == 0 {
+			oldEmployee.ManagerID = nil
+		} else {
+			oldEmployee.ManagerID = updateData.ManagerID
+		}
+	}
+	if updateData.JobTitle != nil {
+		oldEmployee.JobTitle = *updateData.JobTitle
+	}
+	if updateData.Phone != nil {
+		oldEmployee.Phone = *updateData.Phone
+	}
+	if updateData.EmployeeNumber != nil {
+		if *updateData.EmployeeNumber == "" {
+			oldEmployee.EmployeeNumber = nil
+		} else {
+			oldEmployee.EmployeeNumber = updateData.EmployeeNumber
+		}
+	}
+	if updateData.Office != nil {
+		oldEmployee.Office = *updateData.Office
+	}
+	if updateData.Building != nil {
+		oldEmployee.Building = *updateData.Building
+	}
+	if updateData.Floor != nil {
+		oldEmployee.Floor = *updateData.Floor
+	}
+	if updateData.TimeZone != nil {
+		oldEmployee.TimeZone = *updateData.TimeZone
+	}
+	if updateData.Locale != nil {
+		oldEmployee.Locale = *updateData.Locale
+	}
+
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(oldEmployee).Error; err != nil {
+			return err
+		}
+		if !transferred {
+			return nil
+		}
+		return tx.Model(&model.Division{}).
+			Where("head_id = ? AND id <> ?", oldEmployee.ID, oldEmployee.DivisionID).
+			Update("head_id", nil).
+			Error
+	})
+	if err != nil {
+		return nil, duplicate(err)
+	}
 
 	if err := r.Db.
 		WithContext(ctx).
-		Save(oldEmployee).
 		Preload("Division").
 		Preload("Role").
 		Find(oldEmployee).
@@ -150,9 +232,237 @@ func (r *employee) Edit(ctx context.Context, oldEmployee *model.Employee, update
 	return oldEmployee, nil
 }
 
+func (r *employee) EditPassword(ctx context.Context, employee *model.Employee, hashedPassword string) (*model.Employee, error) {
+	if err := r.Db.WithContext(ctx).Model(employee).Update("password", hashedPassword).Error; err != nil {
+		return nil, err
+	}
+	return employee, nil
+}
+
+func (r *employee) EditRoleAssignment(ctx context.Context, employee *model.Employee, roleID uint, scopeDivisionID *uint) (*model.Employee, error) {
+	err := r.Db.WithContext(ctx).
+		Model(employee).
+		Updates(map[string]interface{}{"role_id": roleID, "scope_division_id": scopeDivisionID}).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	employee.RoleID = roleID
+	employee.ScopeDivisionID = scopeDivisionID
+	return employee, nil
+}
+
+// Destroy deletes the employee, who also stops heading their division.
 func (r *employee) Destroy(ctx context.Context, employee *model.Employee) (*model.Employee, error) {
-	if err := r.Db.WithContext(ctx).Delete(employee).Error; err != nil {
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Division{}).Where("head_id = ?", employee.ID).Update("head_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(employee).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return employee, nil
 }
+
+// FindAllWithRelations lists every employee with their role and division,
+// restricted to a single division when divisionID is not nil.
+func (r *employee) FindAllWithRelations(ctx context.Context, divisionID *uint) ([]model.Employee, error) {
+	var employees []model.Employee
+	query := r.Db.WithContext(ctx).Preload("Role").Preload("Division")
+	if divisionID != nil {
+		query = query.Where("division_id = ?", *divisionID)
+	}
+	if err := query.Find(&employees).Error; err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+// FindManagerChain returns the manager of the employee, the manager of that
+// manager and so on, the nearest first.
+func (r *employee) FindManagerChain(ctx context.Context, id uint) ([]model.Employee, error) {
+	var managers []model.Employee
+	err := r.Db.WithContext(ctx).Raw(`
+		WITH RECURSIVE chain (id, manager_id, depth) AS (
+			SELECT id, manager_id, 0 FROM employees WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT e.id, e.manager_id, chain.depth + 1
+			FROM employees e JOIN chain ON e.id = chain.manager_id
+			WHERE e.deleted_at IS NULL AND chain.depth < ?
+		)
+		SELECT employees.* FROM employees JOIN chain ON chain.id = employees.id
+		WHERE chain.depth > 0
+		ORDER BY chain.depth`, id, MaxReportingDepth).
+		Scan(&managers).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return managers, nil
+}
+
+func (r *employee) FindDirectReports(ctx context.Context, id uint) ([]model.Employee, error) {
+	var reports []model.Employee
+	if err := r.Db.WithContext(ctx).Where("manager_id = ?", id).Order("fullname").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// FindReports returns every employee reporting to the manager, directly or
+// through at most depth levels of management, level by level.
+func (r *employee) FindReports(ctx context.Context, id uint, depth int) ([]model.EmployeeReport, error) {
+	if depth <= 0 || depth > MaxReportingDepth {
+		depth = MaxReportingDepth
+	}
+
+	var reports []model.EmployeeReport
+	err := r.Db.WithContext(ctx).Raw(`
+		WITH RECURSIVE reports (id, depth) AS (
+			SELECT id, 1 FROM employees WHERE manager_id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT e.id, reports.depth + 1
+			FROM employees e JOIN reports ON e.manager_id = reports.id
+			WHERE e.deleted_at IS NULL AND reports.depth < ?
+		)
+		SELECT employees.*, reports.depth FROM employees JOIN reports ON reports.id = employees.id
+		ORDER BY reports.depth, employees.fullname`, id, depth).
+		Scan(&reports).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// EditStatus moves the employee to change.ToStatus and records the change.
+func (r *employee) EditStatus(ctx context.Context, employee *model.Employee, change *model.EmployeeStatusChange) (*model.Employee, error) {
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(employee).
+			Updates(map[string]interface{}{"status": change.ToStatus, "status_effective_at": change.EffectiveAt}).
+			Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	employee.Status = change.ToStatus
+	employee.StatusEffectiveAt = &change.EffectiveAt
+	return employee, nil
+}
+
+// FindStatusChanges returns the status history of the employee, the latest
+// first.
+func (r *employee) FindStatusChanges(ctx context.Context, id uint) ([]model.EmployeeStatusChange, error) {
+	var changes []model.EmployeeStatusChange
+	err := r.Db.WithContext(ctx).
+		Where("employee_id = ?", id).
+		Order("effective_at DESC, id DESC").
+		Find(&changes).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *employee) FindDeletedByID(ctx context.Context, id uint) (model.Employee, error) {
+	var data model.Employee
+	err := r.Db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&data).Error
+	return data, err
+}
+
+// FindByIDUnscoped finds the employee whether it is soft-deleted or not.
+func (r *employee) FindByIDUnscoped(ctx context.Context, id uint) (model.Employee, error) {
+	var data model.Employee
+	err := r.Db.WithContext(ctx).Unscoped().Where("id = ?", id).First(&data).Error
+	return data, err
+}
+
+// FindDeletedBefore returns at most limit employees soft-deleted before the
+// given time, the longest deleted first. Employees Purge would keep are left
+// out, so that they cannot fill every batch.
+func (r *employee) FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Employee, error) {
+	var employees []model.Employee
+	err := r.Db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM api_keys WHERE api_keys.created_by_id = employees.id)").
+		Order("deleted_at").
+		Limit(limit).
+		Find(&employees).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+func (r *employee) Restore(ctx context.Context, employee *model.Employee) (*model.Employee, error) {
+	if err := r.Db.WithContext(ctx).Unscoped().Model(employee).Update("deleted_at", nil).Error; err != nil {
+		return nil, duplicate(err)
+	}
+	employee.DeletedAt = nil
+	return employee, nil
+}
+
+// Purge removes the employee for good, along with their sessions, MFA secrets,
+// invitations and history. Employees they managed lose their manager, and the
+// changes and invitations they made for others no longer name them. An
+// employee who created API keys is kept, as purging them would take the keys
+// with them.
+func (r *employee) Purge(ctx context.Context, employee *model.Employee) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var keys int64
+		if err := tx.Unscoped().Model(&model.APIKey{}).Where("created_by_id = ?", employee.ID).Count(&keys).Error; err != nil {
+			return err
+		}
+		if err := inUse(keys, "api keys"); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&model.Employee{}).Where("manager_id = ?", employee.ID).Update("manager_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Division{}).Where("head_id = ?", employee.ID).Update("head_id", nil).Error; err != nil {
+			return err
+		}
+		actors := []struct {
+			model  interface{}
+			column string
+		}{
+			{&model.EmployeeStatusChange{}, "changed_by_id"},
+			{&model.EmployeePendingChange{}, "scheduled_by_id"},
+			{&model.EmployeePendingChange{}, "canceled_by_id"},
+			{&model.EmployeeInvitation{}, "invited_by_id"},
+		}
+		for _, actor := range actors {
+			if err := tx.Unscoped().Model(actor.model).Where(actor.column+" = ?", employee.ID).Update(actor.column, nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Where("actor_id = ? OR employee_id = ?", employee.ID, employee.ID).Delete(&model.ImpersonationSession{}).Error; err != nil {
+			return err
+		}
+		owned := []interface{}{
+			&model.MFARecoveryCode{},
+			&model.EmployeeMFA{},
+			&model.RefreshToken{},
+			&model.PasswordResetToken{},
+			&model.EmployeeInvitation{},
+			&model.EmployeeStatusChange{},
+			&model.EmployeePendingChange{},
+		}
+		for _, records := range owned {
+			if err := tx.Unscoped().Where("employee_id = ?", employee.ID).Delete(records).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(employee).Error
+	})
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
+	"gorm.io/gorm"
+)
+
+type Impersonation interface {
+	FindAll(ctx context.Context, p *pkgdto.Pagination) ([]model.ImpersonationSession, *pkgdto.PaginationInfo, error)
+	Save(ctx context.Context, session *model.ImpersonationSession) (*model.ImpersonationSession, error)
+	EndByTokenID(ctx context.Context, tokenID string) error
+}
+
+type impersonation struct {
+	Db *gorm.DB
+}
+
+func NewImpersonationRepository(db *gorm.DB) *impersonation {
+	return &impersonation{
+		db,
+	}
+}
+
+// FindAll lists impersonation sessions, the most recent first.
+func (r *impersonation) FindAll(ctx context.Context, pagination *pkgdto.Pagination) ([]model.ImpersonationSession, *pkgdto.PaginationInfo, error) {
+	var sessions []model.ImpersonationSession
+	var count int64
+
+	query := r.Db.WithContext(ctx).Model(&model.ImpersonationSession{})
+
+	countQuery := query
+	if err := countQuery.Count(&count).Error; err != nil {
+		return nil, nil, err
+	}
+
+	limit, offset := pkgdto.GetLimitOffset(pagination)
+
+	err := query.Order("created_at desc").Limit(limit).Offset(offset).Find(&sessions).Error
+
+	return sessions, pkgdto.CheckInfoPagination(pagination, count), err
+}
+
+func (r *impersonation) Save(ctx context.Context, session *model.ImpersonationSession) (*model.ImpersonationSession, error) {
+	if err := r.Db.WithContext(ctx).Save(session).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// EndByTokenID records the end of the session of an impersonation token that
+// was logged out before it expired.
+func (r *impersonation) EndByTokenID(ctx context.Context, tokenID string) error {
+	return r.Db.WithContext(ctx).
+		Model(&model.ImpersonationSession{}).
+		Where("token_id = ? AND ended_at IS NULL", tokenID).
+		Update("ended_at", time.Now()).Error
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"gorm.io/gorm"
+)
+
+type Invitation interface {
+	FindByHash(ctx context.Context, tokenHash string) (*model.EmployeeInvitation, error)
+	Save(ctx context.Context, invitation *model.EmployeeInvitation) (*model.EmployeeInvitation, error)
+	MarkAccepted(ctx context.Context, invitation *model.EmployeeInvitation) (bool, error)
+	InvalidateByEmployeeID(ctx context.Context, employeeID uint) error
+}
+
+type invitation struct {
+	Db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) *invitation {
+	return &invitation{
+		db,
+	}
+}
+
+func (r *invitation) FindByHash(ctx context.Context, tokenHash string) (*model.EmployeeInvitation, error) {
+	var data model.EmployeeInvitation
+	if err := r.Db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (r *invitation) Save(ctx context.Context, invitation *model.EmployeeInvitation) (*model.EmployeeInvitation, error) {
+	if err := r.Db.WithContext(ctx).Save(invitation).Error; err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// MarkAccepted consumes the invitation, reporting false when it was already
+// consumed.
+func (r *invitation) MarkAccepted(ctx context.Context, invitation *model.EmployeeInvitation) (bool, error) {
+	now := time.Now()
+	query := r.Db.WithContext(ctx).
+		Model(&model.EmployeeInvitation{}).
+		Where("id = ? AND accepted_at IS NULL", invitation.ID).
+		Update("accepted_at", now)
+	if err := query.Error; err != nil {
+		return false, err
+	}
+	if query.RowsAffected == 0 {
+		return false, nil
+	}
+	invitation.AcceptedAt = &now
+	return true, nil
+}
+
+// InvalidateByEmployeeID expires every pending invitation of the employee, e.g.
+// before a new one is sent.
+func (r *invitation) InvalidateByEmployeeID(ctx context.Context, employeeID uint) error {
+	return r.Db.WithContext(ctx).
+		Model(&model.EmployeeInvitation{}).
+		Where("employee_id = ? AND accepted_at IS NULL AND expires_at > ?", employeeID, time.Now()).
+		Update("expires_at", time.Now()).
+		Error
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"gorm.io/gorm"
+)
+
+type MFA interface {
+	FindByEmployeeID(ctx context.Context, employeeID uint) (*model.EmployeeMFA, error)
+	Save(ctx context.Context, mfa *model.EmployeeMFA) (*model.EmployeeMFA, error)
+	Enable(ctx context.Context, mfa *model.EmployeeMFA) error
+	MarkStepUsed(ctx context.Context, mfa *model.EmployeeMFA, step int64) (bool, error)
+	Destroy(ctx context.Context, employeeID uint) error
+	ReplaceRecoveryCodes(ctx context.Context, employeeID uint, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, employeeID uint, codeHash string) (bool, error)
+}
+
+type mfa struct {
+	Db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) *mfa {
+	return &mfa{
+		db,
+	}
+}
+
+func (r *mfa) FindByEmployeeID(ctx context.Context, employeeID uint) (*model.EmployeeMFA, error) {
+	var data model.EmployeeMFA
+	if err := r.Db.WithContext(ctx).Where("employee_id = ?", employeeID).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (r *mfa) Save(ctx context.Context, mfa *model.EmployeeMFA) (*model.EmployeeMFA, error) {
+	if err := r.Db.WithContext(ctx).Save(mfa).Error; err != nil {
+		return nil, err
+	}
+	return mfa, nil
+}
+
+func (r *mfa) Enable(ctx context.Context, mfa *model.EmployeeMFA) error {
+	now := time.Now()
+	if err := r.Db.WithContext(ctx).Model(mfa).Update("enabled_at", now).Error; err != nil {
+		return err
+	}
+	mfa.EnabledAt = &now
+	return nil
+}
+
+// MarkStepUsed records the TOTP step of an accepted code, reporting false when
+// that step or a later one was already used so a code cannot be replayed.
+func (r *mfa) MarkStepUsed(ctx context.Context, mfa *model.EmployeeMFA, step int64) (bool, error) {
+	query := r.Db.WithContext(ctx).
+		Model(&model.EmployeeMFA{}).
+		Where("id = ? AND last_used_step < ?", mfa.ID, step).
+		Update("last_used_step", step)
+	if err := query.Error; err != nil {
+		return false, err
+	}
+	if query.RowsAffected == 0 {
+		return false, nil
+	}
+	mfa.LastUsedStep = step
+	return true, nil
+}
+
+// Destroy removes the secret and recovery codes for good, so the employee can enroll again.
+func (r *mfa) Destroy(ctx context.Context, employeeID uint) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("employee_id = ?", employeeID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("employee_id = ?", employeeID).Delete(&model.EmployeeMFA{}).Error
+	})
+}
+
+func (r *mfa) ReplaceRecoveryCodes(ctx context.Context, employeeID uint, codeHashes []string) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("employee_id = ?", employeeID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]model.MFARecoveryCode, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			codes = append(codes, model.MFARecoveryCode{EmployeeID: employeeID, CodeHash: codeHash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode consumes a recovery code, reporting false when it does not exist or was already used.
+func (r *mfa) UseRecoveryCode(ctx context.Context, employeeID uint, codeHash string) (bool, error) {
+	query := r.Db.WithContext(ctx).
+		Model(&model.MFARecoveryCode{}).
+		Where("employee_id = ? AND code_hash = ? AND used_at IS NULL", employeeID, codeHash).
+		Update("used_at", time.Now())
+	if err := query.Error; err != nil {
+		return false, err
+	}
+	return query.RowsAffected > 0, nil
+}
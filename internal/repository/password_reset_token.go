@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"gorm.io/gorm"
+)
+
+type PasswordResetToken interface {
+	FindByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	Save(ctx context.Context, token *model.PasswordResetToken) (*model.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, token *model.PasswordResetToken) (bool, error)
+	InvalidateByEmployeeID(ctx context.Context, employeeID uint) error
+}
+
+type passwordResetToken struct {
+	Db *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) *passwordResetToken {
+	return &passwordResetToken{
+		db,
+	}
+}
+
+func (r *passwordResetToken) FindByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	var data model.PasswordResetToken
+	if err := r.Db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (r *passwordResetToken) Save(ctx context.Context, token *model.PasswordResetToken) (*model.PasswordResetToken, error) {
+	if err := r.Db.WithContext(ctx).Save(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// MarkUsed consumes the token, reporting false when it was already consumed.
+func (r *passwordResetToken) MarkUsed(ctx context.Context, token *model.PasswordResetToken) (bool, error) {
+	now := time.Now()
+	query := r.Db.WithContext(ctx).
+		Model(&model.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", now)
+	if err := query.Error; err != nil {
+		return false, err
+	}
+	if query.RowsAffected == 0 {
+		return false, nil
+	}
+	token.UsedAt = &now
+	return true, nil
+}
+
+func (r *passwordResetToken) InvalidateByEmployeeID(ctx context.Context, employeeID uint) error {
+	return r.Db.WithContext(ctx).
+		Model(&model.PasswordResetToken{}).
+		Where("employee_id = ? AND used_at IS NULL", employeeID).
+		Update("used_at", time.Now()).
+		Error
+}
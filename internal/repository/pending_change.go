@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PendingChange interface {
+	FindByID(ctx context.Context, id uint) (*model.EmployeePendingChange, error)
+	FindByEmployeeID(ctx context.Context, employeeID uint, state string) ([]model.EmployeePendingChange, error)
+	FindDue(ctx context.Context, now time.Time, limit int) ([]model.EmployeePendingChange, error)
+	Save(ctx context.Context, change *model.EmployeePendingChange) (*model.EmployeePendingChange, error)
+	Cancel(ctx context.Context, change *model.EmployeePendingChange, canceledByID uint) (bool, error)
+	MarkFailed(ctx context.Context, change *model.EmployeePendingChange, reason string) (bool, error)
+	Apply(ctx context.Context, change *model.EmployeePendingChange, employee *model.Employee) (bool, error)
+}
+
+type pendingChange struct {
+	Db *gorm.DB
+}
+
+func NewPendingChangeRepository(db *gorm.DB) *pendingChange {
+	return &pendingChange{
+		db,
+	}
+}
+
+func (r *pendingChange) FindByID(ctx context.Context, id uint) (*model.EmployeePendingChange, error) {
+	var data model.EmployeePendingChange
+	if err := r.Db.WithContext(ctx).First(&data, id).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// FindByEmployeeID returns the changes scheduled for the employee, the next
+// one first. An empty state returns changes in any state.
+func (r *pendingChange) FindByEmployeeID(ctx context.Context, employeeID uint, state string) ([]model.EmployeePendingChange, error) {
+	query := r.Db.WithContext(ctx).Where("employee_id = ?", employeeID)
+	if state != "" {
+		query = query.Where("state = ?", state)
+	}
+
+	var changes []model.EmployeePendingChange
+	if err := query.Order("effective_at, id").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// FindDue returns at most limit pending changes whose effective date has been
+// reached by now, the oldest first.
+func (r *pendingChange) FindDue(ctx context.Context, now time.Time, limit int) ([]model.EmployeePendingChange, error) {
+	var changes []model.EmployeePendingChange
+	err := r.Db.WithContext(ctx).
+		Where("state = ? AND effective_at <= ?", enum.PendingChangePending, now).
+		Order("effective_at, id").
+		Limit(limit).
+		Find(&changes).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *pendingChange) Save(ctx context.Context, change *model.EmployeePendingChange) (*model.EmployeePendingChange, error) {
+	if err := r.Db.WithContext(ctx).Save(change).Error; err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Cancel withdraws the change, reporting false when it is no longer pending.
+func (r *pendingChange) Cancel(ctx context.Context, change *model.EmployeePendingChange, canceledByID uint) (bool, error) {
+	ok, err := r.finish(r.Db.WithContext(ctx), change, map[string]interface{}{
+		"state":          enum.PendingChangeCanceled,
+		"canceled_by_id": canceledByID,
+	})
+	if ok {
+		change.State = string(enum.PendingChangeCanceled)
+		change.CanceledByID = &canceledByID
+	}
+	return ok, err
+}
+
+// MarkFailed gives up on a due change that cannot be made, reporting false
+// when it is no longer pending.
+func (r *pendingChange) MarkFailed(ctx context.Context, change *model.EmployeePendingChange, reason string) (bool, error) {
+	ok, err := r.finish(r.Db.WithContext(ctx), change, map[string]interface{}{
+		"state": enum.PendingChangeFailed,
+		"error": reason,
+	})
+	if ok {
+		change.State = string(enum.PendingChangeFailed)
+		change.Error = reason
+	}
+	return ok, err
+}
+
+// Apply makes the change to the employee and marks it applied in one
+// transaction, reporting false when the change is no longer pending. The
+// employee is read again under a row lock, and the change is marked failed
+// instead when the status it sets can no longer be reached from the current
+// one. Marking the change locks its row until the transaction ends, so when
+// several instances pick up the same change only one of them applies it.
+func (r *pendingChange) Apply(ctx context.Context, change *model.EmployeePendingChange, employee *model.Employee) (bool, error) {
+	now := time.Now()
+	applied := false
+	reason := ""
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current model.Employee
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, employee.ID).Error; err != nil {
+			return err
+		}
+		from := current.Status
+		if change.Status != nil && *change.Status != from {
+			if to := enum.EmployeeStatus(*change.Status); !enum.EmployeeStatus(from).CanTransitionTo(to) {
+				reason = fmt.Sprintf("status cannot change from %s to %s", from, to)
+				_, err := r.finish(tx, change, map[string]interface{}{
+					"state": enum.PendingChangeFailed,
+					"error": reason,
+				})
+				return err
+			}
+		}
+
+		ok, err := r.finish(tx, change, map[string]interface{}{
+			"state":      enum.PendingChangeApplied,
+			"applied_at": now,
+		})
+		if err != nil || !ok {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		transferred := change.DivisionID != nil && *change.DivisionID != current.DivisionID
+		if change.DivisionID != nil {
+			updates["division_id"] = *change.DivisionID
+		}
+		if change.RoleID != nil {
+			updates["role_id"] = *change.RoleID
+		}
+		if change.Status != nil {
+			updates["status"] = *change.Status
+			updates["status_effective_at"] = change.EffectiveAt
+		}
+		if err := tx.Model(&current).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if transferred {
+			err := tx.Model(&model.Division{}).
+				Where("head_id = ? AND id <> ?", current.ID, *change.DivisionID).
+				Update("head_id", nil).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+		if change.Status != nil && *change.Status != from {
+			err := tx.Create(&model.EmployeeStatusChange{
+				EmployeeID:  current.ID,
+				FromStatus:  from,
+				ToStatus:    *change.Status,
+				EffectiveAt: change.EffectiveAt,
+				Reason:      change.Reason,
+				ChangedByID: change.ScheduledByID,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		*employee = current
+		applied = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if reason != "" {
+		change.State = string(enum.PendingChangeFailed)
+		change.Error = reason
+	}
+	if !applied {
+		return false, nil
+	}
+
+	change.State = string(enum.PendingChangeApplied)
+	change.AppliedAt = &now
+	return true, nil
+}
+
+// finish moves the change out of the pending state, reporting false when it
+// already was.
+func (r *pendingChange) finish(db *gorm.DB, change *model.EmployeePendingChange, updates map[string]interface{}) (bool, error) {
+	query := db.Model(&model.EmployeePendingChange{}).
+		Where("id = ? AND state = ?", change.ID, enum.PendingChangePending).
+		Updates(updates)
+	if err := query.Error; err != nil {
+		return false, err
+	}
+	return query.RowsAffected > 0, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"gorm.io/gorm"
+)
+
+type Permission interface {
+	FindAll(ctx context.Context) ([]model.Permission, error)
+	FindByNames(ctx context.Context, names []string) ([]model.Permission, error)
+}
+
+type permission struct {
+	Db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) *permission {
+	return &permission{
+		db,
+	}
+}
+
+func (r *permission) FindAll(ctx context.Context) ([]model.Permission, error) {
+	var permissions []model.Permission
+	if err := r.Db.WithContext(ctx).Order("name").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (r *permission) FindByNames(ctx context.Context, names []string) ([]model.Permission, error) {
+	var permissions []model.Permission
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	if err := r.Db.WithContext(ctx).Where("name IN ?", names).Order("name").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"gorm.io/gorm"
+)
+
+type RefreshToken interface {
+	FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	Save(ctx context.Context, token *model.RefreshToken) (*model.RefreshToken, error)
+	MarkUsed(ctx context.Context, token *model.RefreshToken) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeByEmployeeID(ctx context.Context, employeeID uint) error
+}
+
+type refreshToken struct {
+	Db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *refreshToken {
+	return &refreshToken{
+		db,
+	}
+}
+
+func (r *refreshToken) FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	var data model.RefreshToken
+	if err := r.Db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (r *refreshToken) Save(ctx context.Context, token *model.RefreshToken) (*model.RefreshToken, error) {
+	if err := r.Db.WithContext(ctx).Save(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// MarkUsed flags the token as consumed. It reports false when another request
+// already used or revoked the token, which callers must treat as reuse.
+func (r *refreshToken) MarkUsed(ctx context.Context, token *model.RefreshToken) (bool, error) {
+	now := time.Now()
+	query := r.Db.WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", token.ID).
+		Update("used_at", now)
+	if err := query.Error; err != nil {
+		return false, err
+	}
+	if query.RowsAffected == 0 {
+		return false, nil
+	}
+	token.UsedAt = &now
+	return true, nil
+}
+
+func (r *refreshToken) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.Db.WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).
+		Error
+}
+
+func (r *refreshToken) RevokeByEmployeeID(ctx context.Context, employeeID uint) error {
+	return r.Db.WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("employee_id = ? AND revoked_at IS NULL", employeeID).
+		Update("revoked_at", time.Now()).
+		Error
+}
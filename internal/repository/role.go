@@ -3,20 +3,34 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/dto"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/model"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/enum"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/constant"
 	pkgdto "github.com/Alterra-DataOn-Kelompok-5/employee-service/pkg/dto"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Role interface {
-	FindAll(ctx context.Context, payload *pkgdto.SearchGetRequest, p *pkgdto.Pagination) ([]model.Role, *pkgdto.PaginationInfo, error)
+	FindAll(ctx context.Context, payload *dto.RoleSearchRequest, p *pkgdto.Pagination) ([]model.Role, *pkgdto.PaginationInfo, error)
 	FindByID(ctx context.Context, id uint) (model.Role, error)
 	Save(ctx context.Context, role *dto.CreateRoleRequestBody) (model.Role, error)
 	Edit(ctx context.Context, oldrole *model.Role, updateData *dto.UpdateRoleRequestBody) (*model.Role, error)
 	Destroy(ctx context.Context, role *model.Role) (*model.Role, error)
 	ExistByName(ctx context.Context, name string) (bool, error)
+	FindAncestors(ctx context.Context, id uint) ([]model.Role, error)
+	ReplacePermissions(ctx context.Context, role *model.Role, permissions []model.Permission) error
+	FindDeletedByID(ctx context.Context, id uint) (model.Role, error)
+	FindByIDUnscoped(ctx context.Context, id uint) (model.Role, error)
+	FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Role, error)
+	Restore(ctx context.Context, role *model.Role) (*model.Role, error)
+	Purge(ctx context.Context, role *model.Role) error
+	FindUsage(ctx context.Context, id uint) (model.RoleUsage, error)
+	ReassignAndDestroy(ctx context.Context, role *model.Role, targetID uint) ([]uint, error)
+	FindHolders(ctx context.Context, id uint) ([]uint, error)
 }
 
 type role struct {
@@ -29,11 +43,11 @@ func NewRoleRepository(db *gorm.DB) *role {
 	}
 }
 
-func (r *role) FindAll(ctx context.Context, payload *pkgdto.SearchGetRequest, pagination *pkgdto.Pagination) ([]model.Role, *pkgdto.PaginationInfo, error) {
+func (r *role) FindAll(ctx context.Context, payload *dto.RoleSearchRequest, pagination *pkgdto.Pagination) ([]model.Role, *pkgdto.PaginationInfo, error) {
 	var roles []model.Role
 	var count int64
 
-	query := r.Db.WithContext(ctx).Model(&model.Role{})
+	query := withDeleted(r.Db.WithContext(ctx).Model(&model.Role{}), payload.DeletedFilter)
 
 	if payload.Search != "" {
 		search := "%" + strings.ToLower(payload.Search) + "%"
@@ -47,14 +61,14 @@ func (r *role) FindAll(ctx context.Context, payload *pkgdto.SearchGetRequest, pa
 
 	limit, offset := pkgdto.GetLimitOffset(pagination)
 
-	err := query.Limit(limit).Offset(offset).Find(&roles).Error
+	err := query.Preload("Permissions").Limit(limit).Offset(offset).Find(&roles).Error
 
 	return roles, pkgdto.CheckInfoPagination(pagination, count), err
 }
 
 func (r *role) FindByID(ctx context.Context, id uint) (model.Role, error) {
 	var role model.Role
-	if err := r.Db.WithContext(ctx).Model(&model.Role{}).Where("id = ?", id).First(&role).Error; err != nil {
+	if err := r.Db.WithContext(ctx).Model(&model.Role{}).Preload("Permissions").Where("id = ?", id).First(&role).Error; err != nil {
 		return role, err
 	}
 	return role, nil
@@ -62,10 +76,11 @@ func (r *role) FindByID(ctx context.Context, id uint) (model.Role, error) {
 
 func (r *role) Save(ctx context.Context, role *dto.CreateRoleRequestBody) (model.Role, error) {
 	newRole := model.Role{
-		Name: *role.Name,
+		Name:     *role.Name,
+		ParentID: role.ParentID,
 	}
 	if err := r.Db.WithContext(ctx).Save(&newRole).Error; err != nil {
-		return newRole, err
+		return newRole, duplicate(err)
 	}
 	return newRole, nil
 }
@@ -74,9 +89,16 @@ func (r *role) Edit(ctx context.Context, oldRole *model.Role, updateData *dto.Up
 	if updateData.Name != nil {
 		oldRole.Name = *updateData.Name
 	}
+	if updateData.ParentID != nil {
+		if *updateData.ParentID == 0 {
+			oldRole.ParentID = nil
+		} else {
+			oldRole.ParentID = updateData.ParentID
+		}
+	}
 
-	if err := r.Db.WithContext(ctx).Save(oldRole).Find(oldRole).Error; err != nil {
-		return nil, err
+	if err := r.Db.WithContext(ctx).Omit(clause.Associations).Save(oldRole).Error; err != nil {
+		return nil, duplicate(err)
 	}
 
 	return oldRole, nil
@@ -102,3 +124,180 @@ func (r *role) ExistByName(ctx context.Context, name string) (bool, error) {
 	}
 	return isExist, nil
 }
+
+// FindAncestors returns the parent of the role, the parent of that parent and
+// so on, the nearest first. The walk stops at a deleted parent, and at a role
+// already visited so that a cycle in the table cannot loop forever.
+func (r *role) FindAncestors(ctx context.Context, id uint) ([]model.Role, error) {
+	current, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ancestors []model.Role
+	visited := map[uint]bool{id: true}
+	for current.ParentID != nil && !visited[*current.ParentID] {
+		visited[*current.ParentID] = true
+		parent, err := r.FindByID(ctx, *current.ParentID)
+		if err != nil {
+			if err == constant.RECORD_NOT_FOUND {
+				break
+			}
+			return nil, err
+		}
+		ancestors = append(ancestors, parent)
+		current = parent
+	}
+	return ancestors, nil
+}
+
+// FindHolders returns the IDs of the employees, soft-deleted ones included,
+// holding the role or a role inheriting from it, however deep. The walk down
+// skips roles already visited so that a cycle in the table cannot loop
+// forever.
+func (r *role) FindHolders(ctx context.Context, id uint) ([]uint, error) {
+	roleIDs := []uint{id}
+	visited := map[uint]bool{id: true}
+	for level := []uint{id}; len(level) > 0; {
+		var children []uint
+		if err := r.Db.WithContext(ctx).Unscoped().Model(&model.Role{}).Where("parent_id IN ?", level).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		level = nil
+		for _, child := range children {
+			if !visited[child] {
+				visited[child] = true
+				roleIDs = append(roleIDs, child)
+				level = append(level, child)
+			}
+		}
+	}
+
+	var holders []uint
+	if err := r.Db.WithContext(ctx).Unscoped().Model(&model.Employee{}).Where("role_id IN ?", roleIDs).Pluck("id", &holders).Error; err != nil {
+		return nil, err
+	}
+	return holders, nil
+}
+
+func (r *role) ReplacePermissions(ctx context.Context, role *model.Role, permissions []model.Permission) error {
+	if err := r.Db.WithContext(ctx).Model(role).Association("Permissions").Replace(permissions); err != nil {
+		return err
+	}
+	role.Permissions = permissions
+	return nil
+}
+
+func (r *role) FindDeletedByID(ctx context.Context, id uint) (model.Role, error) {
+	var data model.Role
+	err := r.Db.WithContext(ctx).Unscoped().Preload("Permissions").Where("id = ? AND deleted_at IS NOT NULL", id).First(&data).Error
+	return data, err
+}
+
+// FindByIDUnscoped finds the role whether it is soft-deleted or not.
+func (r *role) FindByIDUnscoped(ctx context.Context, id uint) (model.Role, error) {
+	var data model.Role
+	err := r.Db.WithContext(ctx).Unscoped().Preload("Permissions").Where("id = ?", id).First(&data).Error
+	return data, err
+}
+
+// FindDeletedBefore returns at most limit roles soft-deleted before the given
+// time, the longest deleted first. Roles Purge would keep are left out, so
+// that they cannot fill every batch.
+func (r *role) FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Role, error) {
+	var roles []model.Role
+	err := r.Db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM employees WHERE employees.role_id = roles.id)").
+		Where("NOT EXISTS (SELECT 1 FROM roles children WHERE children.parent_id = roles.id)").
+		Order("deleted_at").
+		Limit(limit).
+		Find(&roles).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *role) Restore(ctx context.Context, role *model.Role) (*model.Role, error) {
+	if err := r.Db.WithContext(ctx).Unscoped().Model(role).Omit(clause.Associations).Update("deleted_at", nil).Error; err != nil {
+		return nil, duplicate(err)
+	}
+	role.DeletedAt = nil
+	return role, nil
+}
+
+// Purge removes the role and its grants for good, unless employees or child
+// roles, even soft-deleted ones, still refer to it.
+func (r *role) Purge(ctx context.Context, role *model.Role) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var employees, children int64
+		if err := tx.Unscoped().Model(&model.Employee{}).Where("role_id = ?", role.ID).Count(&employees).Error; err != nil {
+			return err
+		}
+		if err := inUse(employees, "employees"); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Role{}).Where("parent_id = ?", role.ID).Count(&children).Error; err != nil {
+			return err
+		}
+		if err := inUse(children, "child roles"); err != nil {
+			return err
+		}
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(role).Error
+	})
+}
+
+// FindUsage counts the employees, child roles and pending changes referring to
+// the role. Soft-deleted records are not counted.
+func (r *role) FindUsage(ctx context.Context, id uint) (model.RoleUsage, error) {
+	var usage model.RoleUsage
+	db := r.Db.WithContext(ctx)
+	if err := db.Model(&model.Employee{}).Where("role_id = ?", id).Count(&usage.Employees).Error; err != nil {
+		return usage, err
+	}
+	if err := db.Model(&model.Role{}).Where("parent_id = ?", id).Count(&usage.ChildRoles).Error; err != nil {
+		return usage, err
+	}
+	err := db.Model(&model.EmployeePendingChange{}).
+		Where("role_id = ? AND state = ?", id, enum.PendingChangePending).
+		Count(&usage.PendingChanges).
+		Error
+	return usage, err
+}
+
+// ReassignAndDestroy gives the target role to the employees holding the role,
+// soft-deleted ones included, and to the pending changes granting it, makes
+// the target the parent of its child roles, then deletes the role, all in one
+// transaction. It returns the IDs of the employees moved.
+func (r *role) ReassignAndDestroy(ctx context.Context, role *model.Role, targetID uint) ([]uint, error) {
+	var moved []uint
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Employee{}).Where("role_id = ?", role.ID).Pluck("id", &moved).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Employee{}).Where("role_id = ?", role.ID).Update("role_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Role{}).Where("parent_id = ?", role.ID).Update("parent_id", targetID).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&model.EmployeePendingChange{}).
+			Where("role_id = ? AND state = ?", role.ID, enum.PendingChangePending).
+			Update("role_id", targetID).
+			Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(role).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
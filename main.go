@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/migration"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/database/seeder"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/auth"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/employee"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/app/retention"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/factory"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/http"
 	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/middleware"
+	"github.com/Alterra-DataOn-Kelompok-5/employee-service/internal/pkg/util"
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 )
@@ -18,6 +23,15 @@ func init() {
 	if err := godotenv.Load(); err != nil {
 		panic(err)
 	}
+	// settings read while packages were initialised missed .env
+	if err := util.LoadJWTConfig(); err != nil {
+		panic(err)
+	}
+	if err := auth.LoadConfig(); err != nil {
+		panic(err)
+	}
+	employee.LoadConfig()
+	retention.LoadConfig()
 	database.GetConnection()
 }
 
@@ -69,5 +83,8 @@ to use this flag:
 
 	http.NewHttp(e, f)
 
+	go employee.NewScheduler(f).Run(context.Background())
+	go retention.NewJob(f).Run(context.Background())
+
 	e.Logger.Fatal(e.Start(":" + os.Getenv("APP_PORT")))
 }
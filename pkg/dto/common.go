@@ -32,6 +32,23 @@ type ByIDRequest struct {
 	ID uint `param:"id" validate:"required"`
 }
 
+// DeleteRequest soft-deletes the record, or with Hard removes it for good.
+type DeleteRequest struct {
+	ByIDRequest
+	Hard bool `query:"hard"`
+}
+
+// DeletedFilter lists soft-deleted records along with the others, or only
+// them.
+type DeletedFilter struct {
+	IncludeDeleted bool `query:"include_deleted"`
+	OnlyDeleted    bool `query:"only_deleted"`
+}
+
+func (f DeletedFilter) Any() bool {
+	return f.IncludeDeleted || f.OnlyDeleted
+}
+
 func GetLimitOffset(p *Pagination) (limit, offset int) {
 
 	if p.PageSize != nil {
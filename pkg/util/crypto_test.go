@@ -0,0 +1,26 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	asserts := assert.New(t)
+	key := DeriveKey("testsecret")
+
+	ciphertext, err := Encrypt("JBSWY3DPEHPK3PXP", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asserts.NotContains(ciphertext, "JBSWY3DPEHPK3PXP")
+
+	plaintext, err := Decrypt(ciphertext, key)
+	if asserts.NoError(err) {
+		asserts.Equal("JBSWY3DPEHPK3PXP", plaintext)
+	}
+
+	_, err = Decrypt(ciphertext, DeriveKey("othersecret"))
+	asserts.Error(err)
+}
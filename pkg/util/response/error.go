@@ -11,8 +11,9 @@ import (
 )
 
 type errorResponse struct {
-	Meta  Meta   `json:"meta"`
-	Error string `json:"error"`
+	Meta    Meta        `json:"meta"`
+	Error   string      `json:"error"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 type Error struct {
@@ -23,15 +24,18 @@ type Error struct {
 
 const (
 	E_DUPLICATE            = "duplicate"
+	E_CONFLICT             = "conflict"
 	E_NOT_FOUND            = "not_found"
 	E_UNPROCESSABLE_ENTITY = "unprocessable_entity"
 	E_UNAUTHORIZED         = "unauthorized"
 	E_BAD_REQUEST          = "bad_request"
 	E_SERVER_ERROR         = "server_error"
+	E_TOO_MANY_REQUESTS    = "too_many_requests"
 )
 
 type errorConstant struct {
 	Duplicate                Error
+	Conflict                 Error
 	NotFound                 Error
 	RouteNotFound            Error
 	UnprocessableEntity      Error
@@ -39,7 +43,10 @@ type errorConstant struct {
 	BadRequest               Error
 	Validation               Error
 	InternalServerError      Error
+	TooManyRequests          Error
 	EmailOrPasswordIncorrect Error
+	PasswordIncorrect        Error
+	MFACodeIncorrect         Error
 	ConvertionNotFound       Error
 	NotEnoughStock           Error
 }
@@ -55,6 +62,16 @@ var ErrorConstant errorConstant = errorConstant{
 		},
 		Code: http.StatusConflict,
 	},
+	Conflict: Error{
+		Response: errorResponse{
+			Meta: Meta{
+				Success: false,
+				Message: "Data is still referenced by other data",
+			},
+			Error: E_CONFLICT,
+		},
+		Code: http.StatusConflict,
+	},
 	EmailOrPasswordIncorrect: Error{
 		Response: errorResponse{
 			Meta: Meta{
@@ -65,6 +82,26 @@ var ErrorConstant errorConstant = errorConstant{
 		},
 		Code: http.StatusBadRequest,
 	},
+	PasswordIncorrect: Error{
+		Response: errorResponse{
+			Meta: Meta{
+				Success: false,
+				Message: "Current password is incorrect",
+			},
+			Error: E_BAD_REQUEST,
+		},
+		Code: http.StatusBadRequest,
+	},
+	MFACodeIncorrect: Error{
+		Response: errorResponse{
+			Meta: Meta{
+				Success: false,
+				Message: "Authentication code is incorrect",
+			},
+			Error: E_BAD_REQUEST,
+		},
+		Code: http.StatusBadRequest,
+	},
 	NotFound: Error{
 		Response: errorResponse{
 			Meta: Meta{
@@ -125,6 +162,16 @@ var ErrorConstant errorConstant = errorConstant{
 		},
 		Code: http.StatusBadRequest,
 	},
+	TooManyRequests: Error{
+		Response: errorResponse{
+			Meta: Meta{
+				Success: false,
+				Message: "Too many failed attempts, please try again later",
+			},
+			Error: E_TOO_MANY_REQUESTS,
+		},
+		Code: http.StatusTooManyRequests,
+	},
 	InternalServerError: Error{
 		Response: errorResponse{
 			Meta: Meta{
@@ -142,6 +189,15 @@ func ErrorBuilder(res *Error, message error) *Error {
 	return res
 }
 
+// ErrorWithDetailsBuilder works like ErrorBuilder but attaches structured details,
+// e.g. per-rule validation failures, to a copy of res so the shared constant stays untouched.
+func ErrorWithDetailsBuilder(res *Error, message error, details interface{}) *Error {
+	e := *res
+	e.Response.Details = details
+	e.ErrorMessage = message
+	return &e
+}
+
 func CustomErrorBuilder(code int, err string, message string) *Error {
 	return &Error{
 		Response: errorResponse{
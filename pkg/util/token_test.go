@@ -0,0 +1,26 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateRandomToken(t *testing.T) {
+	token1, err := GenerateRandomToken(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := GenerateRandomToken(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, token1, 43)
+	assert.NotEqual(t, token1, token2)
+}
+
+func TestHashToken(t *testing.T) {
+	hashed := HashToken("abc")
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", hashed)
+	assert.Equal(t, hashed, HashToken("abc"))
+}
@@ -1,6 +1,10 @@
 package util
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
 
 func Getenv(key, fallback string) string {
 	var (
@@ -13,3 +17,45 @@ func Getenv(key, fallback string) string {
 	}
 	return val
 }
+
+// GetenvDuration reads a duration such as "15m" or "168h" from the environment,
+// returning fallback when the variable is missing or malformed.
+func GetenvDuration(key string, fallback time.Duration) time.Duration {
+	val, isExist := os.LookupEnv(key)
+	if !isExist {
+		return fallback
+	}
+	duration, err := time.ParseDuration(val)
+	if err != nil {
+		return fallback
+	}
+	return duration
+}
+
+// GetenvInt reads an integer from the environment, returning fallback when the
+// variable is missing or malformed.
+func GetenvInt(key string, fallback int) int {
+	val, isExist := os.LookupEnv(key)
+	if !isExist {
+		return fallback
+	}
+	number, err := strconv.Atoi(val)
+	if err != nil {
+		return fallback
+	}
+	return number
+}
+
+// GetenvBool reads a boolean such as "true" or "0" from the environment,
+// returning fallback when the variable is missing or malformed.
+func GetenvBool(key string, fallback bool) bool {
+	val, isExist := os.LookupEnv(key)
+	if !isExist {
+		return fallback
+	}
+	boolean, err := strconv.ParseBool(val)
+	if err != nil {
+		return fallback
+	}
+	return boolean
+}
@@ -2,18 +2,47 @@ package util
 
 import (
 	"net/http"
+	"regexp"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
 )
 
+var localeRegex = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
 type CustomValidator struct {
 	Validator *validator.Validate
 }
 
+// NewValidator returns a validator that also knows the timezone tag, for IANA
+// time zone names such as Asia/Jakarta, and the locale tag, for language tags
+// such as en or id-ID.
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterValidation("timezone", isTimeZone)
+	v.RegisterValidation("locale", isLocale)
+	return v
+}
+
 func (cv *CustomValidator) Validate(i interface{}) error {
 	if err := cv.Validator.Struct(i); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func isTimeZone(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+	// LoadLocation accepts "" and "Local", which are not zone names
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+func isLocale(fl validator.FieldLevel) bool {
+	return localeRegex.MatchString(fl.Field().String())
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type profile struct {
+	Phone    string `validate:"omitempty,e164"`
+	TimeZone string `validate:"omitempty,timezone"`
+	Locale   string `validate:"omitempty,locale"`
+}
+
+func TestValidatorProfile(t *testing.T) {
+	v := NewValidator()
+
+	assert.NoError(t, v.Struct(profile{Phone: "+6281234567890", TimeZone: "Asia/Jakarta", Locale: "id-ID"}))
+	assert.NoError(t, v.Struct(profile{}))
+	assert.Error(t, v.Struct(profile{Phone: "081234567890"}))
+	assert.Error(t, v.Struct(profile{TimeZone: "Mars/Olympus"}))
+	assert.Error(t, v.Struct(profile{TimeZone: "Local"}))
+	assert.Error(t, v.Struct(profile{Locale: "english"}))
+}